@@ -4,4 +4,5 @@ package constants
 const (
 	LivePeerSubgraphEndpoint = "https://api.thegraph.com/subgraphs/name/livepeer/arbitrum-one"
 	ClientIDTemplate         = "%s (livepeer-exporter)"
+	SubgraphPageSize         = 1000 // The maximum number of entities the subgraph returns per query.
 )
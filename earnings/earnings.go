@@ -0,0 +1,173 @@
+// Package earnings implements a tax-ready earnings report of the Livepeer orchestrator. It combines
+// the winning tickets and rewards data of the orch_tickets_exporter and orch_rewards_exporter with
+// historical crypto prices to value each earning at the price on the day it was received.
+package earnings
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"livepeer-exporter/exporters/orch_rewards_exporter"
+	"livepeer-exporter/exporters/orch_tickets_exporter"
+	"livepeer-exporter/prices"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// csvHeader contains the column names of the earnings CSV report.
+var csvHeader = []string{
+	"type",
+	"transaction_hash",
+	"timestamp",
+	"round",
+	"asset",
+	"amount",
+	"gas_cost_eth",
+	"currency",
+	"asset_price",
+	"value",
+	"gas_cost_value",
+}
+
+// record represents a single earning in the earnings report.
+type record struct {
+	Type      string    // The type of earning (i.e. 'ticket' or 'reward').
+	ID        string    // The transaction hash.
+	Timestamp time.Time // The block time of the transaction.
+	Round     float64   // The round in which the earning was received.
+	Asset     string    // The asset that was earned (i.e. 'ETH' or 'LPT').
+	Amount    float64   // The amount of the asset that was earned.
+	GasCost   float64   // The gas cost of the transaction in ETH.
+}
+
+// EarningsReport creates earnings reports from the orchestrator's winning tickets and rewards.
+type EarningsReport struct {
+	// Config settings.
+	currency string // The fiat currency used to value the earnings.
+
+	// Data sources.
+	ticketsExporter *orch_tickets_exporter.OrchTicketsExporter // Provides the winning tickets.
+	rewardsExporter *orch_rewards_exporter.OrchRewardsExporter // Provides the rewards.
+	priceProvider   *prices.HistoricalPriceProvider            // Provides the historical prices.
+}
+
+// records returns the winning tickets and rewards as earnings records sorted by time.
+func (r *EarningsReport) records() []record {
+	var records []record
+	for _, ticket := range r.ticketsExporter.Tickets() {
+		records = append(records, record{
+			Type:      "ticket",
+			ID:        ticket.ID,
+			Timestamp: ticket.Timestamp,
+			Round:     ticket.Round,
+			Asset:     "ETH",
+			Amount:    ticket.FaceValue,
+			GasCost:   ticket.GasCost,
+		})
+	}
+	for _, reward := range r.rewardsExporter.Rewards() {
+		records = append(records, record{
+			Type:      "reward",
+			ID:        reward.ID,
+			Timestamp: reward.Timestamp,
+			Round:     reward.Round,
+			Asset:     "LPT",
+			Amount:    reward.RewardTokens,
+			GasCost:   reward.GasCost,
+		})
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Timestamp.Before(records[j].Timestamp)
+	})
+	return records
+}
+
+// formatFloat formats a float64 for the CSV report.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// WriteCSV writes the earnings report as CSV to the given writer. Each winning ticket and reward is
+// written as a separate row and valued at the price on the day it was received. It returns an error if
+// not all winning tickets or rewards were fetched, since the report would silently miss earnings.
+func (r *EarningsReport) WriteCSV(w io.Writer) error {
+	if !r.ticketsExporter.Complete() {
+		return fmt.Errorf("winning tickets are incomplete")
+	}
+	if !r.rewardsExporter.Complete() {
+		return fmt.Errorf("rewards are incomplete")
+	}
+
+	// Persist the prices fetched for the report at once.
+	defer func() {
+		if err := r.priceProvider.Save(); err != nil {
+			log.Printf("Error saving price cache: %v", err)
+		}
+	}()
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return fmt.Errorf("error writing CSV header: %w", err)
+	}
+
+	for _, rec := range r.records() {
+		assetPrice, err := r.priceProvider.GetPrice(rec.Asset, r.currency, rec.Timestamp)
+		if err != nil {
+			return fmt.Errorf("error retrieving price for '%s': %w", rec.ID, err)
+		}
+		ethPrice, err := r.priceProvider.GetPrice("ETH", r.currency, rec.Timestamp)
+		if err != nil {
+			return fmt.Errorf("error retrieving ETH price for '%s': %w", rec.ID, err)
+		}
+
+		row := []string{
+			rec.Type,
+			rec.ID,
+			rec.Timestamp.UTC().Format(time.RFC3339),
+			formatFloat(rec.Round),
+			rec.Asset,
+			formatFloat(rec.Amount),
+			formatFloat(rec.GasCost),
+			r.currency,
+			formatFloat(assetPrice),
+			formatFloat(rec.Amount * assetPrice),
+			formatFloat(rec.GasCost * ethPrice),
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("error writing CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ServeCSV serves the earnings report as a CSV file download.
+func (r *EarningsReport) ServeCSV(w http.ResponseWriter, req *http.Request) {
+	// Create the report before writing the response so that errors can still be reported.
+	var buf bytes.Buffer
+	if err := r.WriteCSV(&buf); err != nil {
+		log.Printf("Error creating earnings report: %v", err)
+		http.Error(w, "failed to create earnings report", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename=earnings.csv")
+	buf.WriteTo(w)
+}
+
+// NewEarningsReport creates a new EarningsReport.
+func NewEarningsReport(currency string, ticketsExporter *orch_tickets_exporter.OrchTicketsExporter, rewardsExporter *orch_rewards_exporter.OrchRewardsExporter, priceProvider *prices.HistoricalPriceProvider) *EarningsReport {
+	return &EarningsReport{
+		currency:        currency,
+		ticketsExporter: ticketsExporter,
+		rewardsExporter: rewardsExporter,
+		priceProvider:   priceProvider,
+	}
+}
@@ -27,8 +27,8 @@ type cryptoPricesResponse struct {
 	}
 }
 
-// cryptoPrices represents the structure of the data returned by the API, parsed into a struct.
-type cryptoPrices struct {
+// CryptoPrices represents the structure of the data returned by the API, parsed into a struct.
+type CryptoPrices struct {
 	LPTUSDPrice float64
 	ETHUSDPrice float64
 	LPTEURPrice float64
@@ -48,7 +48,7 @@ type CryptoPricesExporter struct {
 
 	// Data.
 	cryptoPricesResponse *cryptoPricesResponse // The data returned by the API.
-	cryptoPrices         *cryptoPrices         // The data returned by the  API, parsed into a struct.
+	cryptoPrices         *CryptoPrices         // The data returned by the  API, parsed into a struct.
 
 	// Fetchers.
 	cryptoPricesFetcher fetcher.Fetcher
@@ -121,7 +121,7 @@ func NewCryptoPricesExporter(fetchInterval time.Duration, updateInterval time.Du
 		updateInterval:       updateInterval,
 		cryptoPricesEndpoint: getCryptoPricesEndpoint,
 		cryptoPricesResponse: &cryptoPricesResponse{},
-		cryptoPrices:         &cryptoPrices{},
+		cryptoPrices:         &CryptoPrices{},
 	}
 
 	// Initialize fetcher.
@@ -137,6 +137,13 @@ func NewCryptoPricesExporter(fetchInterval time.Duration, updateInterval time.Du
 	return exporter
 }
 
+// CryptoPrices returns the latest parsed crypto prices.
+func (m *CryptoPricesExporter) CryptoPrices() CryptoPrices {
+	m.cryptoPricesResponse.Mutex.Lock()
+	defer m.cryptoPricesResponse.Mutex.Unlock()
+	return *m.cryptoPrices
+}
+
 func (m *CryptoPricesExporter) Start() {
 	// Fetch initial data and update metrics.
 	m.cryptoPricesFetcher.FetchData()
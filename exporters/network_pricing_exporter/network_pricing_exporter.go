@@ -0,0 +1,267 @@
+// Package network_pricing_exporter implements a Livepeer network pricing exporter that fetches the active
+// orchestrators from the Livepeer subgraph GraphQL API endpoint and their prices from the Livepeer orchestrator
+// score API and exposes the network-wide price per pixel distribution via Prometheus metrics.
+package network_pricing_exporter
+
+import (
+	"fmt"
+	"livepeer-exporter/constants"
+	"livepeer-exporter/exporters/orch_score_exporter"
+	"livepeer-exporter/fetcher"
+	"livepeer-exporter/util"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	activeTranscodersEndpoint = constants.LivePeerSubgraphEndpoint
+
+	// The percentiles of the price per pixel distribution that are exposed.
+	pricePercentiles = []float64{10, 25, 75, 90}
+)
+
+// graphqlQuery represents the GraphQL query to fetch data from the GraphQL API.
+const graphqlQuery = `
+{
+	transcoders(where: {active: true}, first: 1000) {
+		id
+	}
+}
+`
+
+// activeTranscodersResponse represents the structure of the GraphQL API response.
+type activeTranscodersResponse struct {
+	sync.Mutex
+
+	// Response data.
+	Data struct {
+		Transcoders []struct {
+			ID string
+		}
+	}
+}
+
+// orchPrices represents the price per pixel of each orchestrator.
+type orchPrices struct {
+	sync.Mutex
+
+	// Prices per pixel in Wei keyed by orchestrator address.
+	Prices map[string]float64
+}
+
+// NetworkPricingExporter fetches data from the APIs and exposes the network-wide price distribution via Prometheus.
+type NetworkPricingExporter struct {
+	// Metrics.
+	MinPrice            prometheus.Gauge
+	MaxPrice            prometheus.Gauge
+	MedianPrice         prometheus.Gauge
+	PricePercentile     *prometheus.GaugeVec
+	PricedOrchCount     prometheus.Gauge
+	OrchPricePercentile prometheus.Gauge
+
+	// Config settings.
+	orchAddress                   string        // The orchestrator address.
+	fetchInterval                 time.Duration // How often to fetch data.
+	updateInterval                time.Duration // How often to update metrics.
+	activeTranscodersEndpoint     string        // The endpoint to fetch the active orchestrators from.
+	activeTranscodersGraphqlQuery string        // The GraphQL query to fetch data from the GraphQL API.
+	headers                       map[string][]string
+
+	// Data.
+	activeTranscoders *activeTranscodersResponse // The data returned by the subgraph API.
+	orchPrices        *orchPrices                // The prices returned by the score API.
+
+	// Fetchers.
+	activeTranscodersFetcher fetcher.Fetcher
+}
+
+// initMetrics initializes the network pricing metrics.
+func (m *NetworkPricingExporter) initMetrics() {
+	m.MinPrice = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_network_price_per_pixel_min",
+			Help: "The lowest price per pixel of the active orchestrators in Wei.",
+		},
+	)
+	m.MaxPrice = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_network_price_per_pixel_max",
+			Help: "The highest price per pixel of the active orchestrators in Wei.",
+		},
+	)
+	m.MedianPrice = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_network_price_per_pixel_median",
+			Help: "The median price per pixel of the active orchestrators in Wei.",
+		},
+	)
+	m.PricePercentile = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_network_price_per_pixel_percentile",
+			Help: "The price per pixel percentiles of the active orchestrators in Wei.",
+		},
+		[]string{"percentile"},
+	)
+	m.PricedOrchCount = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_network_price_per_pixel_orchestrator_count",
+			Help: "The number of active orchestrators with a known price per pixel.",
+		},
+	)
+	m.OrchPricePercentile = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_price_per_pixel_percentile",
+			Help: "The percentile position of the orchestrator's price per pixel among the active orchestrators.",
+		},
+	)
+}
+
+// registerMetrics registers the network pricing metrics with Prometheus.
+func (m *NetworkPricingExporter) registerMetrics() {
+	prometheus.MustRegister(
+		m.MinPrice,
+		m.MaxPrice,
+		m.MedianPrice,
+		m.PricePercentile,
+		m.PricedOrchCount,
+		m.OrchPricePercentile,
+	)
+}
+
+// fetchPrice fetches the price per pixel of the given orchestrator from the Livepeer orchestrator score API.
+func (m *NetworkPricingExporter) fetchPrice(address string) (float64, error) {
+	orchScore := &orch_score_exporter.OrchScore{}
+	orchScoreFetcher := fetcher.Fetcher{
+		URL:     orch_score_exporter.GetOrchScoreEndpoint(address),
+		Data:    &orchScore,
+		Headers: m.headers,
+	}
+	if err := orchScoreFetcher.FetchData(); err != nil {
+		return 0, err
+	}
+	return orchScore.PricePerPixel, nil
+}
+
+// fetchData fetches the active orchestrators and their prices.
+func (m *NetworkPricingExporter) fetchData() {
+	// Retrieve the active orchestrators.
+	m.activeTranscoders.Mutex.Lock()
+	m.activeTranscodersFetcher.FetchGraphQLData(m.activeTranscodersGraphqlQuery)
+	addresses := []string{m.orchAddress}
+	for _, transcoder := range m.activeTranscoders.Data.Transcoders {
+		if transcoder.ID != m.orchAddress {
+			addresses = append(addresses, transcoder.ID)
+		}
+	}
+	m.activeTranscoders.Mutex.Unlock()
+
+	// Retrieve the price of each orchestrator.
+	prices := make(map[string]float64, len(addresses))
+	for _, address := range addresses {
+		price, err := m.fetchPrice(address)
+		if err != nil {
+			log.Printf("Error fetching price of orchestrator '%s': %v", address, err)
+			continue
+		}
+		prices[address] = price
+	}
+
+	m.orchPrices.Mutex.Lock()
+	m.orchPrices.Prices = prices
+	m.orchPrices.Mutex.Unlock()
+}
+
+// updateMetrics updates the metrics with the fetched prices.
+func (m *NetworkPricingExporter) updateMetrics() {
+	// Collect the prices of the active orchestrators. Orchestrators without a price are skipped since
+	// they are not reachable by the score API.
+	m.activeTranscoders.Mutex.Lock()
+	m.orchPrices.Mutex.Lock()
+	var prices []float64
+	for _, transcoder := range m.activeTranscoders.Data.Transcoders {
+		if price := m.orchPrices.Prices[transcoder.ID]; price > 0 {
+			prices = append(prices, price)
+		}
+	}
+	orchPrice := m.orchPrices.Prices[m.orchAddress]
+	m.orchPrices.Mutex.Unlock()
+	m.activeTranscoders.Mutex.Unlock()
+
+	// Set the metrics.
+	m.MinPrice.Set(util.Percentile(prices, 0))
+	m.MaxPrice.Set(util.Percentile(prices, 100))
+	m.MedianPrice.Set(util.Median(prices))
+	for _, percentile := range pricePercentiles {
+		m.PricePercentile.WithLabelValues(strconv.FormatFloat(percentile, 'f', -1, 64)).Set(util.Percentile(prices, percentile))
+	}
+	m.PricedOrchCount.Set(float64(len(prices)))
+
+	// Skip the percentile position while the orchestrator's price is unknown, since a price of 0 would
+	// always rank lowest.
+	if orchPrice <= 0 {
+		return
+	}
+	m.OrchPricePercentile.Set(util.PercentileRank(prices, orchPrice))
+}
+
+// NewNetworkPricingExporter creates a new NetworkPricingExporter.
+func NewNetworkPricingExporter(orchAddress string, fetchInterval time.Duration, updateInterval time.Duration) *NetworkPricingExporter {
+	exporter := &NetworkPricingExporter{
+		orchAddress:                   orchAddress,
+		fetchInterval:                 fetchInterval,
+		updateInterval:                updateInterval,
+		activeTranscodersEndpoint:     activeTranscodersEndpoint,
+		activeTranscodersGraphqlQuery: graphqlQuery,
+		activeTranscoders:             &activeTranscodersResponse{},
+		orchPrices:                    &orchPrices{},
+	}
+
+	// Create request headers.
+	exporter.headers = map[string][]string{
+		"X-Device-ID": {fmt.Sprintf(constants.ClientIDTemplate, orchAddress)},
+	}
+
+	// Initialize fetcher.
+	exporter.activeTranscodersFetcher = fetcher.Fetcher{
+		URL:     exporter.activeTranscodersEndpoint,
+		Data:    &exporter.activeTranscoders,
+		Headers: exporter.headers,
+	}
+
+	// Initialize metrics.
+	exporter.initMetrics()
+	exporter.registerMetrics()
+
+	return exporter
+}
+
+// Start starts the NetworkPricingExporter.
+func (m *NetworkPricingExporter) Start() {
+	// Fetch initial data and update metrics.
+	m.fetchData()
+	m.updateMetrics()
+
+	// Start fetcher in a goroutine.
+	go func() {
+		ticker := time.NewTicker(m.fetchInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			m.fetchData()
+		}
+	}()
+
+	// Start metrics updater in a goroutine.
+	go func() {
+		ticker := time.NewTicker(m.updateInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			m.updateMetrics()
+		}
+	}()
+}
@@ -0,0 +1,586 @@
+// Package orch_bond_events_exporter implements a Livepeer orchestrator bond events exporter that fetches
+// the bond, unbond, rebond and transfer bond events of the orchestrator's delegators from the Livepeer
+// subgraph GraphQL API endpoint and exposes the stake movements via Prometheus metrics.
+package orch_bond_events_exporter
+
+import (
+	"fmt"
+	"livepeer-exporter/constants"
+	"livepeer-exporter/fetcher"
+	"livepeer-exporter/util"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	bondEventsEndpoint = constants.LivePeerSubgraphEndpoint
+
+	// The stake movement types.
+	movementTypes = []string{"bond", "move_in", "move_out", "unbond", "rebond", "transfer_bond"}
+)
+
+// transferBondDelegatorsChunkSize is the number of delegators of which the transfer bond events are
+// fetched per query, to keep the size of the delegator filter limited.
+const transferBondDelegatorsChunkSize = 100
+
+// bondEventsQueryTemplate represents the GraphQL query to fetch a page of bond events matching the given
+// filter from the GraphQL API. The events are ordered by ID so that the last ID of a page can be used as
+// the cursor of the next page.
+const bondEventsQueryTemplate = `
+{
+	events: bondEvents(where: {%s, id_gt: "%s"}, orderBy: id, orderDirection: asc, first: %d) {
+		id
+		transaction {
+			id
+			timestamp
+		}
+		round {
+			id
+		}
+		delegator {
+			id
+		}
+		oldDelegate {
+			id
+		}
+		bondedAmount
+		additionalAmount
+	}
+}
+`
+
+// amountEventsQueryTemplate represents the GraphQL query to fetch a page of unbond or rebond events of
+// the given orchestrator from the GraphQL API. The first argument is the name of the events field.
+const amountEventsQueryTemplate = `
+{
+	events: %s(where: {delegate: "%s", id_gt: "%s"}, orderBy: id, orderDirection: asc, first: %d) {
+		id
+		transaction {
+			id
+			timestamp
+		}
+		round {
+			id
+		}
+		delegator {
+			id
+		}
+		amount
+	}
+}
+`
+
+// transferBondQueryTemplate represents the GraphQL query to fetch a page of transfer bond events matching
+// the given delegator filter from the GraphQL API. Transfer bond events do not reference the delegate, so
+// they are fetched by delegator and attributed to the orchestrator using the bond events.
+const transferBondQueryTemplate = `
+{
+	events: transferBondEvents(where: {%s, id_gt: "%s"}, orderBy: id, orderDirection: asc, first: %d) {
+		id
+		transaction {
+			id
+			timestamp
+		}
+		round {
+			id
+		}
+		oldDelegator {
+			id
+		}
+		newDelegator {
+			id
+		}
+		amount
+	}
+}
+`
+
+// event represents the fields shared by all bond events contained in the GraphQL API response.
+type event struct {
+	ID          string
+	Transaction struct {
+		ID        string
+		Timestamp int
+	}
+	Round struct {
+		ID string
+	}
+}
+
+// eventID returns the ID of the event, which is used as the pagination cursor.
+func (e event) eventID() string {
+	return e.ID
+}
+
+// eventsResponse represents the structure of a GraphQL API response containing a page of events.
+type eventsResponse[T any] struct {
+	Data struct {
+		Events []T
+	}
+}
+
+// bondEvent represents the structure of the bondEvents field contained in the GraphQL API response.
+type bondEvent struct {
+	event
+	Delegator struct {
+		ID string
+	}
+	OldDelegate *struct {
+		ID string
+	}
+	BondedAmount     string
+	AdditionalAmount string
+}
+
+// amountEvent represents the structure of the unbondEvents and rebondEvents fields contained in the
+// GraphQL API response.
+type amountEvent struct {
+	event
+	Delegator struct {
+		ID string
+	}
+	Amount string
+}
+
+// transferBondEvent represents the structure of the transferBondEvents field contained in the GraphQL API response.
+type transferBondEvent struct {
+	event
+	OldDelegator struct {
+		ID string
+	}
+	NewDelegator struct {
+		ID string
+	}
+	Amount string
+}
+
+// delegation represents a change of the delegate of a delegator.
+type delegation struct {
+	Timestamp int  // The block time of the bond event.
+	ToOrch    bool // Whether the delegator delegated to the orchestrator.
+}
+
+// bondEventsResponse represents the structure of the GraphQL API response.
+type bondEventsResponse struct {
+	sync.Mutex
+
+	// Response data.
+	Data struct {
+		BondsIn      []bondEvent
+		BondsOut     []bondEvent
+		UnbondEvents []amountEvent
+		RebondEvents []amountEvent
+	}
+
+	// The transfer bond events of the orchestrator's delegators.
+	TransferBondEvents []transferBondEvent
+
+	// Whether all events were fetched.
+	Complete bool
+}
+
+// movement represents a parsed stake movement.
+type movement struct {
+	EventID   string    // The ID of the event.
+	ID        string    // The transaction hash of the event.
+	Type      string    // The type of stake movement.
+	Delegator string    // The address of the delegator whose stake moved.
+	Timestamp time.Time // The block time of the event.
+	Round     float64   // The round in which the event occurred.
+	Amount    float64   // The amount of LPT that moved.
+}
+
+// newMovement creates a movement from the shared event fields.
+func newMovement(e event, movementType string, delegator string, amount float64) movement {
+	round, _ := strconv.ParseFloat(e.Round.ID, 64)
+	return movement{
+		EventID:   e.ID,
+		ID:        e.Transaction.ID,
+		Type:      movementType,
+		Delegator: delegator,
+		Timestamp: time.Unix(int64(e.Transaction.Timestamp), 0),
+		Round:     round,
+		Amount:    amount,
+	}
+}
+
+// OrchBondEventsExporter fetches data from the API and exposes the orchestrator's stake movements via Prometheus.
+type OrchBondEventsExporter struct {
+	// Metrics.
+	EventCount      *prometheus.GaugeVec
+	EventAmount     *prometheus.GaugeVec
+	LargestMovement *prometheus.GaugeVec
+
+	// Config settings.
+	orchAddress            string        // The orchestrator address to filter events by.
+	fetchInterval          time.Duration // How often to fetch data.
+	updateInterval         time.Duration // How often to update metrics.
+	largestMovementsCount  int           // The number of largest recent movements to expose.
+	logThreshold           float64       // The LPT amount above which movements are logged. Disabled if 0.
+	orchBondEventsEndpoint string        // The endpoint to fetch data from.
+
+	// Data.
+	orchBondEvents *bondEventsResponse // The data returned by the API.
+	seenEvents     map[string]bool     // The IDs of the events that were already processed.
+
+	// Fetchers.
+	orchBondEventsFetcher fetcher.Fetcher
+}
+
+// initMetrics initializes the orchestrator bond events metrics.
+func (m *OrchBondEventsExporter) initMetrics() {
+	m.EventCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_bond_event_count",
+			Help: "The number of stake movements per type and period.",
+		},
+		[]string{"type", "period"},
+	)
+	m.EventAmount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_bond_event_amount",
+			Help: "The amount of LPT moved per type and period.",
+		},
+		[]string{"type", "period"},
+	)
+	m.LargestMovement = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_largest_stake_movement_amount",
+			Help: "The amount of LPT of the largest stake movements in the last 30 days.",
+		},
+		[]string{"id", "type", "delegator"},
+	)
+}
+
+// registerMetrics registers the orchestrator bond events metrics with Prometheus.
+func (m *OrchBondEventsExporter) registerMetrics() {
+	prometheus.MustRegister(
+		m.EventCount,
+		m.EventAmount,
+		m.LargestMovement,
+	)
+}
+
+// parseMovements parses the events in the response data into stake movements.
+func (m *OrchBondEventsExporter) parseMovements() []movement {
+	var movements []movement
+	for _, e := range m.orchBondEvents.Data.BondsIn {
+		var bondedAmount, additionalAmount float64
+		util.SetFloatFromStr(&bondedAmount, e.BondedAmount)
+		util.SetFloatFromStr(&additionalAmount, e.AdditionalAmount)
+
+		// Delegators that switch from another orchestrator bring their full stake.
+		if e.OldDelegate != nil && e.OldDelegate.ID != "" && e.OldDelegate.ID != m.orchAddress {
+			movements = append(movements, newMovement(e.event, "move_in", e.Delegator.ID, bondedAmount))
+		} else {
+			movements = append(movements, newMovement(e.event, "bond", e.Delegator.ID, additionalAmount))
+		}
+	}
+	for _, e := range m.orchBondEvents.Data.BondsOut {
+		var bondedAmount, additionalAmount float64
+		util.SetFloatFromStr(&bondedAmount, e.BondedAmount)
+		util.SetFloatFromStr(&additionalAmount, e.AdditionalAmount)
+		movements = append(movements, newMovement(e.event, "move_out", e.Delegator.ID, bondedAmount-additionalAmount))
+	}
+	for _, e := range m.orchBondEvents.Data.UnbondEvents {
+		var amount float64
+		util.SetFloatFromStr(&amount, e.Amount)
+		movements = append(movements, newMovement(e.event, "unbond", e.Delegator.ID, amount))
+	}
+	for _, e := range m.orchBondEvents.Data.RebondEvents {
+		var amount float64
+		util.SetFloatFromStr(&amount, e.Amount)
+		movements = append(movements, newMovement(e.event, "rebond", e.Delegator.ID, amount))
+	}
+	for _, e := range m.orchBondEvents.TransferBondEvents {
+		var amount float64
+		util.SetFloatFromStr(&amount, e.Amount)
+		movements = append(movements, newMovement(e.event, "transfer_bond", e.NewDelegator.ID, amount))
+	}
+	return movements
+}
+
+// getDelegations returns the delegate changes of each delegator in the given bond events keyed by
+// delegator address and sorted by time.
+func getDelegations(bondsIn []bondEvent, bondsOut []bondEvent) map[string][]delegation {
+	delegations := make(map[string][]delegation)
+	for _, e := range bondsIn {
+		delegations[e.Delegator.ID] = append(delegations[e.Delegator.ID], delegation{Timestamp: e.Transaction.Timestamp, ToOrch: true})
+	}
+	for _, e := range bondsOut {
+		delegations[e.Delegator.ID] = append(delegations[e.Delegator.ID], delegation{Timestamp: e.Transaction.Timestamp, ToOrch: false})
+	}
+	for _, d := range delegations {
+		sort.SliceStable(d, func(i, j int) bool {
+			return d[i].Timestamp < d[j].Timestamp
+		})
+	}
+	return delegations
+}
+
+// isDelegatedAt returns whether the delegator was delegated to the orchestrator at the given time,
+// which is the case when its last bond event up to that time bonded to the orchestrator.
+func isDelegatedAt(delegations []delegation, timestamp int) bool {
+	delegated := false
+	for _, d := range delegations {
+		if d.Timestamp > timestamp {
+			break
+		}
+		delegated = d.ToOrch
+	}
+	return delegated
+}
+
+// getOrchTransfers returns the transfer bond events in which the sending or receiving delegator was
+// delegated to the orchestrator at the time of the transfer. Duplicate events are skipped.
+func getOrchTransfers(transfers []transferBondEvent, delegations map[string][]delegation) []transferBondEvent {
+	seen := make(map[string]bool, len(transfers))
+	var orchTransfers []transferBondEvent
+	for _, e := range transfers {
+		if seen[e.ID] {
+			continue
+		}
+		seen[e.ID] = true
+		timestamp := e.Transaction.Timestamp
+		if isDelegatedAt(delegations[e.OldDelegator.ID], timestamp) || isDelegatedAt(delegations[e.NewDelegator.ID], timestamp) {
+			orchTransfers = append(orchTransfers, e)
+		}
+	}
+	return orchTransfers
+}
+
+// fetchEvents fetches all events of the query built by the given function page by page. The function
+// receives the cursor of the page.
+func fetchEvents[T interface{ eventID() string }](f fetcher.Fetcher, query func(cursor string) string) ([]T, error) {
+	var events []T
+	for {
+		var cursor string
+		if len(events) > 0 {
+			cursor = events[len(events)-1].eventID()
+		}
+		page := &eventsResponse[T]{}
+		f.Data = page
+		if err := f.FetchGraphQLData(query(cursor)); err != nil {
+			return nil, fmt.Errorf("error fetching events after '%s': %w", cursor, err)
+		}
+		events = append(events, page.Data.Events...)
+		if len(page.Data.Events) < constants.SubgraphPageSize {
+			return events, nil
+		}
+	}
+}
+
+// fetchBondEvents fetches all bond events matching the given filter.
+func (m *OrchBondEventsExporter) fetchBondEvents(filter string) ([]bondEvent, error) {
+	return fetchEvents[bondEvent](m.orchBondEventsFetcher, func(cursor string) string {
+		return fmt.Sprintf(bondEventsQueryTemplate, filter, cursor, constants.SubgraphPageSize)
+	})
+}
+
+// fetchAmountEvents fetches all unbond or rebond events of the orchestrator.
+func (m *OrchBondEventsExporter) fetchAmountEvents(field string) ([]amountEvent, error) {
+	return fetchEvents[amountEvent](m.orchBondEventsFetcher, func(cursor string) string {
+		return fmt.Sprintf(amountEventsQueryTemplate, field, m.orchAddress, cursor, constants.SubgraphPageSize)
+	})
+}
+
+// fetchTransferBondEvents fetches all transfer bond events sent or received by the given delegators. The
+// delegators are queried in chunks.
+func (m *OrchBondEventsExporter) fetchTransferBondEvents(delegators []string) ([]transferBondEvent, error) {
+	var transfers []transferBondEvent
+	for start := 0; start < len(delegators); start += transferBondDelegatorsChunkSize {
+		end := start + transferBondDelegatorsChunkSize
+		if end > len(delegators) {
+			end = len(delegators)
+		}
+		chunk := strings.Join(delegators[start:end], ", ")
+		for _, filter := range []string{"oldDelegator_in: [" + chunk + "]", "newDelegator_in: [" + chunk + "]"} {
+			events, err := fetchEvents[transferBondEvent](m.orchBondEventsFetcher, func(cursor string) string {
+				return fmt.Sprintf(transferBondQueryTemplate, filter, cursor, constants.SubgraphPageSize)
+			})
+			if err != nil {
+				return nil, err
+			}
+			transfers = append(transfers, events...)
+		}
+	}
+	return transfers, nil
+}
+
+// fetchData fetches all bond, unbond and rebond events of the orchestrator and the transfer bond events of
+// its delegators. The data is only replaced when all queries succeeded.
+func (m *OrchBondEventsExporter) fetchData() {
+	response := &bondEventsResponse{}
+	var err error
+	quotedAddress := strconv.Quote(m.orchAddress)
+	if response.Data.BondsIn, err = m.fetchBondEvents("newDelegate: " + quotedAddress); err != nil {
+		log.Printf("Error fetching incoming bond events: %v", err)
+		return
+	}
+	if response.Data.BondsOut, err = m.fetchBondEvents("oldDelegate: " + quotedAddress + ", newDelegate_not: " + quotedAddress); err != nil {
+		log.Printf("Error fetching outgoing bond events: %v", err)
+		return
+	}
+	if response.Data.UnbondEvents, err = m.fetchAmountEvents("unbondEvents"); err != nil {
+		log.Printf("Error fetching unbond events: %v", err)
+		return
+	}
+	if response.Data.RebondEvents, err = m.fetchAmountEvents("rebondEvents"); err != nil {
+		log.Printf("Error fetching rebond events: %v", err)
+		return
+	}
+
+	// Fetch the transfer bond events of all delegators that ever bonded to the orchestrator.
+	delegations := getDelegations(response.Data.BondsIn, response.Data.BondsOut)
+	delegators := make([]string, 0, len(delegations))
+	for delegator := range delegations {
+		delegators = append(delegators, strconv.Quote(delegator))
+	}
+	sort.Strings(delegators)
+	transfers, err := m.fetchTransferBondEvents(delegators)
+	if err != nil {
+		log.Printf("Error fetching transfer bond events: %v", err)
+		return
+	}
+
+	m.orchBondEvents.Mutex.Lock()
+	m.orchBondEvents.Data = response.Data
+	m.orchBondEvents.TransferBondEvents = getOrchTransfers(transfers, delegations)
+	m.orchBondEvents.Complete = true
+	m.orchBondEvents.Mutex.Unlock()
+}
+
+// logMovements logs the new movements above the log threshold. Movements present in the first
+// fetched data are only marked as seen.
+func (m *OrchBondEventsExporter) logMovements(movements []movement) {
+	if m.logThreshold <= 0 {
+		return
+	}
+
+	firstRun := m.seenEvents == nil
+	if firstRun {
+		m.seenEvents = make(map[string]bool, len(movements))
+	}
+	for _, mov := range movements {
+		if m.seenEvents[mov.EventID] {
+			continue
+		}
+		m.seenEvents[mov.EventID] = true
+		if !firstRun && mov.Amount >= m.logThreshold {
+			log.Printf("Stake movement of %.2f LPT (type: %s, delegator: %s, round: %.0f, transaction: %s)", mov.Amount, mov.Type, mov.Delegator, mov.Round, mov.ID)
+		}
+	}
+}
+
+// updateMetrics updates the metrics with the data fetched from the Livepeer subgraph GraphQL API. The
+// metrics are only updated once all events were fetched.
+func (m *OrchBondEventsExporter) updateMetrics() {
+	if !m.orchBondEvents.Complete {
+		return
+	}
+
+	movements := m.parseMovements()
+	m.logMovements(movements)
+
+	// Calculate the counts and amounts per type and period.
+	periods := util.GetPeriods(time.Now())
+	for _, movementType := range movementTypes {
+		for _, period := range periods {
+			var count, amount float64
+			for _, mov := range movements {
+				if mov.Type == movementType && !mov.Timestamp.Before(period.Start) {
+					count++
+					amount += mov.Amount
+				}
+			}
+			m.EventCount.WithLabelValues(movementType, period.Name).Set(count)
+			m.EventAmount.WithLabelValues(movementType, period.Name).Set(amount)
+		}
+	}
+
+	// Set the largest movements of the last 30 days.
+	thirtyDaysAgo := time.Now().AddDate(0, -1, 0)
+	var recent []movement
+	for _, mov := range movements {
+		if !mov.Timestamp.Before(thirtyDaysAgo) {
+			recent = append(recent, mov)
+		}
+	}
+	sort.Slice(recent, func(i, j int) bool {
+		return recent[i].Amount > recent[j].Amount
+	})
+	if len(recent) > m.largestMovementsCount {
+		recent = recent[:m.largestMovementsCount]
+	}
+	m.LargestMovement.Reset()
+	for _, mov := range recent {
+		m.LargestMovement.WithLabelValues(mov.ID, mov.Type, mov.Delegator).Set(mov.Amount)
+	}
+}
+
+// NewOrchBondEventsExporter creates a new OrchBondEventsExporter.
+func NewOrchBondEventsExporter(orchAddress string, fetchInterval time.Duration, updateInterval time.Duration, largestMovementsCount int, logThreshold float64) *OrchBondEventsExporter {
+	exporter := &OrchBondEventsExporter{
+		orchAddress:            orchAddress,
+		fetchInterval:          fetchInterval,
+		updateInterval:         updateInterval,
+		largestMovementsCount:  largestMovementsCount,
+		logThreshold:           logThreshold,
+		orchBondEventsEndpoint: bondEventsEndpoint,
+		orchBondEvents:         &bondEventsResponse{},
+	}
+
+	// Create request headers.
+	headers := map[string][]string{
+		"X-Device-ID": {fmt.Sprintf(constants.ClientIDTemplate, orchAddress)},
+	}
+
+	// Initialize fetcher. The target of each query is set when fetching.
+	exporter.orchBondEventsFetcher = fetcher.Fetcher{
+		URL:     exporter.orchBondEventsEndpoint,
+		Headers: headers,
+	}
+
+	// Initialize metrics.
+	exporter.initMetrics()
+	exporter.registerMetrics()
+
+	return exporter
+}
+
+// Start starts the OrchBondEventsExporter.
+func (m *OrchBondEventsExporter) Start() {
+	// Fetch initial data and update metrics.
+	m.fetchData()
+	m.orchBondEvents.Mutex.Lock()
+	m.updateMetrics()
+	m.orchBondEvents.Mutex.Unlock()
+
+	// Start fetcher in a goroutine.
+	go func() {
+		ticker := time.NewTicker(m.fetchInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			m.fetchData()
+		}
+	}()
+
+	// Start metrics updater in a goroutine.
+	go func() {
+		ticker := time.NewTicker(m.updateInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			m.orchBondEvents.Mutex.Lock()
+			m.updateMetrics()
+			m.orchBondEvents.Mutex.Unlock()
+		}
+	}()
+}
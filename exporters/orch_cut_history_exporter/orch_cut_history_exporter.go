@@ -0,0 +1,326 @@
+// Package orch_cut_history_exporter implements a Livepeer orchestrator cut history exporter that fetches the
+// transcoder update events of the orchestrator and the watched orchestrators from the Livepeer subgraph
+// GraphQL API endpoint and exposes the history of their reward and fee cut changes via Prometheus metrics.
+package orch_cut_history_exporter
+
+import (
+	"fmt"
+	"livepeer-exporter/constants"
+	"livepeer-exporter/fetcher"
+	"livepeer-exporter/util"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	transcoderUpdateEventsEndpoint = constants.LivePeerSubgraphEndpoint
+)
+
+// graphqlQueryTemplate represents the GraphQL query to fetch a page of transcoder update events of an
+// orchestrator from the GraphQL API. The events are ordered by ID so that the last ID of a page can be used
+// as the cursor of the next page.
+const graphqlQueryTemplate = `
+{
+	transcoderUpdateEvents(where: {delegate: "%s", id_gt: "%s"}, orderBy: id, orderDirection: asc, first: %d) {
+		id
+		transaction {
+			id
+			timestamp
+		}
+		round {
+			id
+		}
+		delegate {
+			id
+		}
+		rewardCut
+		feeShare
+	}
+}
+`
+
+// transcoderUpdateEvent represents the structure of the transcoderUpdateEvents field contained in the GraphQL API response.
+type transcoderUpdateEvent struct {
+	ID          string
+	Transaction struct {
+		ID        string
+		Timestamp int
+	}
+	Round struct {
+		ID string
+	}
+	Delegate struct {
+		ID string
+	}
+	RewardCut string
+	FeeShare  string
+}
+
+// transcoderUpdateEventsResponse represents the structure of the GraphQL API response.
+type transcoderUpdateEventsResponse struct {
+	Data struct {
+		TranscoderUpdateEvents []transcoderUpdateEvent
+	}
+}
+
+// orchTranscoderUpdateEvents represents the transcoder update events keyed by orchestrator address.
+type orchTranscoderUpdateEvents struct {
+	sync.Mutex
+
+	Events map[string][]transcoderUpdateEvent
+}
+
+// cutChange represents a change of the reward or fee cut of an orchestrator.
+type cutChange struct {
+	Timestamp         time.Time // The block time of the change.
+	Round             float64   // The round in which the change occurred.
+	RewardCut         float64   // The reward cut after the change.
+	FeeCut            float64   // The fee cut after the change.
+	PreviousRewardCut float64   // The reward cut before the change.
+	PreviousFeeCut    float64   // The fee cut before the change.
+}
+
+// getCutChanges returns the cut changes of an orchestrator from all its transcoder update events. The
+// first event sets the initial cuts and is only used as the baseline. Events that do not change the reward
+// or fee cut are skipped. The changes are sorted by time.
+func getCutChanges(events []transcoderUpdateEvent) []cutChange {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Transaction.Timestamp < events[j].Transaction.Timestamp
+	})
+
+	var changes []cutChange
+	var rewardCut, feeCut float64
+	for i, event := range events {
+		var eventRewardCut, feeShare, round float64
+		util.SetFloatFromStr(&eventRewardCut, event.RewardCut)
+		util.SetFloatFromStr(&feeShare, event.FeeShare)
+		util.SetFloatFromStr(&round, event.Round.ID)
+		eventRewardCut = util.Round(eventRewardCut*1e-6, 6) // Cuts are stored in parts per million.
+		eventFeeCut := util.Round(1-feeShare*1e-6, 6)
+
+		if i == 0 {
+			rewardCut, feeCut = eventRewardCut, eventFeeCut
+			continue
+		}
+		if eventRewardCut == rewardCut && eventFeeCut == feeCut {
+			continue
+		}
+		changes = append(changes, cutChange{
+			Timestamp:         time.Unix(int64(event.Transaction.Timestamp), 0),
+			Round:             round,
+			RewardCut:         eventRewardCut,
+			FeeCut:            eventFeeCut,
+			PreviousRewardCut: rewardCut,
+			PreviousFeeCut:    feeCut,
+		})
+		rewardCut, feeCut = eventRewardCut, eventFeeCut
+	}
+	return changes
+}
+
+// OrchCutHistoryExporter fetches data from the API and exposes the cut change history via Prometheus.
+type OrchCutHistoryExporter struct {
+	// Metrics.
+	LastChangeTimestamp *prometheus.GaugeVec
+	LastChangeRound     *prometheus.GaugeVec
+	PreviousRewardCut   *prometheus.GaugeVec
+	PreviousFeeCut      *prometheus.GaugeVec
+	ChangeCount         *prometheus.GaugeVec
+
+	// Config settings.
+	orchestrators          []util.NamedAddress // The orchestrators to fetch the cut history for.
+	fetchInterval          time.Duration       // How often to fetch data.
+	updateInterval         time.Duration       // How often to update metrics.
+	orchCutHistoryEndpoint string              // The endpoint to fetch data from.
+
+	// Data.
+	transcoderUpdateEvents *orchTranscoderUpdateEvents // The data returned by the API.
+
+	// Fetchers.
+	orchCutHistoryFetcher fetcher.Fetcher
+}
+
+// initMetrics initializes the cut history metrics.
+func (m *OrchCutHistoryExporter) initMetrics() {
+	m.LastChangeTimestamp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_cut_last_change_time",
+			Help: "The block time of the last reward or fee cut change of each orchestrator.",
+		},
+		[]string{"orchestrator", "name"},
+	)
+	m.LastChangeRound = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_cut_last_change_round",
+			Help: "The round of the last reward or fee cut change of each orchestrator.",
+		},
+		[]string{"orchestrator", "name"},
+	)
+	m.PreviousRewardCut = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_previous_reward_cut",
+			Help: "The reward cut of each orchestrator before its last cut change.",
+		},
+		[]string{"orchestrator", "name"},
+	)
+	m.PreviousFeeCut = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_previous_fee_cut",
+			Help: "The fee cut of each orchestrator before its last cut change.",
+		},
+		[]string{"orchestrator", "name"},
+	)
+	m.ChangeCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_cut_change_count",
+			Help: "The number of reward or fee cut changes of each orchestrator per period.",
+		},
+		[]string{"orchestrator", "name", "period"},
+	)
+}
+
+// registerMetrics registers the cut history metrics with Prometheus.
+func (m *OrchCutHistoryExporter) registerMetrics() {
+	prometheus.MustRegister(
+		m.LastChangeTimestamp,
+		m.LastChangeRound,
+		m.PreviousRewardCut,
+		m.PreviousFeeCut,
+		m.ChangeCount,
+	)
+}
+
+// fetchEvents fetches all transcoder update events of the given orchestrator page by page.
+func (m *OrchCutHistoryExporter) fetchEvents(orchAddress string) ([]transcoderUpdateEvent, error) {
+	var events []transcoderUpdateEvent
+	for {
+		var cursor string
+		if len(events) > 0 {
+			cursor = events[len(events)-1].ID
+		}
+		page := &transcoderUpdateEventsResponse{}
+		pageFetcher := m.orchCutHistoryFetcher
+		pageFetcher.Data = page
+		if err := pageFetcher.FetchGraphQLData(fmt.Sprintf(graphqlQueryTemplate, orchAddress, cursor, constants.SubgraphPageSize)); err != nil {
+			return nil, fmt.Errorf("error fetching transcoder update events after '%s': %w", cursor, err)
+		}
+		events = append(events, page.Data.TranscoderUpdateEvents...)
+		if len(page.Data.TranscoderUpdateEvents) < constants.SubgraphPageSize {
+			return events, nil
+		}
+	}
+}
+
+// fetchData fetches the transcoder update events of each orchestrator. When the events of an orchestrator
+// could not be fetched, its previously fetched events are kept.
+func (m *OrchCutHistoryExporter) fetchData() {
+	for _, orch := range m.orchestrators {
+		events, err := m.fetchEvents(orch.Address)
+		if err != nil {
+			log.Printf("Error fetching cut history of orchestrator '%s': %v", orch.Address, err)
+			continue
+		}
+		m.transcoderUpdateEvents.Mutex.Lock()
+		m.transcoderUpdateEvents.Events[orch.Address] = events
+		m.transcoderUpdateEvents.Mutex.Unlock()
+	}
+}
+
+// updateMetrics updates the metrics with the data fetched from the Livepeer subgraph GraphQL API.
+func (m *OrchCutHistoryExporter) updateMetrics() {
+	periods := util.GetPeriods(time.Now())
+	for _, orch := range m.orchestrators {
+		events, ok := m.transcoderUpdateEvents.Events[orch.Address]
+		if !ok {
+			continue
+		}
+		changes := getCutChanges(events)
+
+		// Set the last change metrics.
+		if len(changes) > 0 {
+			lastChange := changes[len(changes)-1]
+			m.LastChangeTimestamp.WithLabelValues(orch.Address, orch.Name).Set(float64(lastChange.Timestamp.Unix()) * 1000) // Grafana expects milliseconds.
+			m.LastChangeRound.WithLabelValues(orch.Address, orch.Name).Set(lastChange.Round)
+			m.PreviousRewardCut.WithLabelValues(orch.Address, orch.Name).Set(lastChange.PreviousRewardCut)
+			m.PreviousFeeCut.WithLabelValues(orch.Address, orch.Name).Set(lastChange.PreviousFeeCut)
+		}
+
+		// Count the changes per period.
+		for _, period := range periods {
+			var count float64
+			for _, change := range changes {
+				if !change.Timestamp.Before(period.Start) {
+					count++
+				}
+			}
+			m.ChangeCount.WithLabelValues(orch.Address, orch.Name, period.Name).Set(count)
+		}
+	}
+}
+
+// NewOrchCutHistoryExporter creates a new OrchCutHistoryExporter for the orchestrator and the given watched orchestrators.
+func NewOrchCutHistoryExporter(orchAddress string, watchlist []util.NamedAddress, fetchInterval time.Duration, updateInterval time.Duration) *OrchCutHistoryExporter {
+	orchestrators := append([]util.NamedAddress{{Address: orchAddress, Name: orchAddress}}, watchlist...)
+
+	exporter := &OrchCutHistoryExporter{
+		orchestrators:          orchestrators,
+		fetchInterval:          fetchInterval,
+		updateInterval:         updateInterval,
+		orchCutHistoryEndpoint: transcoderUpdateEventsEndpoint,
+		transcoderUpdateEvents: &orchTranscoderUpdateEvents{
+			Events: make(map[string][]transcoderUpdateEvent),
+		},
+	}
+
+	// Create request headers.
+	headers := map[string][]string{
+		"X-Device-ID": {fmt.Sprintf(constants.ClientIDTemplate, orchAddress)},
+	}
+
+	// Initialize fetcher. The target of each page is set when fetching.
+	exporter.orchCutHistoryFetcher = fetcher.Fetcher{
+		URL:     exporter.orchCutHistoryEndpoint,
+		Headers: headers,
+	}
+
+	// Initialize metrics.
+	exporter.initMetrics()
+	exporter.registerMetrics()
+
+	return exporter
+}
+
+// Start starts the OrchCutHistoryExporter.
+func (m *OrchCutHistoryExporter) Start() {
+	// Fetch initial data and update metrics.
+	m.fetchData()
+	m.transcoderUpdateEvents.Mutex.Lock()
+	m.updateMetrics()
+	m.transcoderUpdateEvents.Mutex.Unlock()
+
+	// Start fetcher in a goroutine.
+	go func() {
+		ticker := time.NewTicker(m.fetchInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			m.fetchData()
+		}
+	}()
+
+	// Start metrics updater in a goroutine.
+	go func() {
+		ticker := time.NewTicker(m.updateInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			m.transcoderUpdateEvents.Mutex.Lock()
+			m.updateMetrics()
+			m.transcoderUpdateEvents.Mutex.Unlock()
+		}
+	}()
+}
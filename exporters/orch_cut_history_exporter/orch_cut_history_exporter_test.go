@@ -0,0 +1,79 @@
+package orch_cut_history_exporter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newTranscoderUpdateEvent returns a transcoder update event with the given block time, round and cuts in
+// parts per million.
+func newTranscoderUpdateEvent(timestamp int, round string, rewardCut string, feeShare string) transcoderUpdateEvent {
+	var event transcoderUpdateEvent
+	event.Transaction.Timestamp = timestamp
+	event.Round.ID = round
+	event.RewardCut = rewardCut
+	event.FeeShare = feeShare
+	return event
+}
+
+func TestGetCutChanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []transcoderUpdateEvent
+		want   []cutChange
+	}{
+		{
+			name: "no events",
+			want: nil,
+		},
+		{
+			name: "first event is the baseline",
+			events: []transcoderUpdateEvent{
+				newTranscoderUpdateEvent(100, "10", "100000", "900000"),
+			},
+			want: nil,
+		},
+		{
+			name: "unchanged cuts are skipped",
+			events: []transcoderUpdateEvent{
+				newTranscoderUpdateEvent(100, "10", "100000", "900000"),
+				newTranscoderUpdateEvent(200, "11", "100000", "900000"),
+			},
+			want: nil,
+		},
+		{
+			name: "changes are sorted by time",
+			events: []transcoderUpdateEvent{
+				newTranscoderUpdateEvent(300, "12", "250000", "500000"),
+				newTranscoderUpdateEvent(100, "10", "100000", "900000"),
+				newTranscoderUpdateEvent(200, "11", "150000", "900000"),
+			},
+			want: []cutChange{
+				{
+					Timestamp:         time.Unix(200, 0),
+					Round:             11,
+					RewardCut:         0.15,
+					FeeCut:            0.1,
+					PreviousRewardCut: 0.1,
+					PreviousFeeCut:    0.1,
+				},
+				{
+					Timestamp:         time.Unix(300, 0),
+					Round:             12,
+					RewardCut:         0.25,
+					FeeCut:            0.5,
+					PreviousRewardCut: 0.15,
+					PreviousFeeCut:    0.1,
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getCutChanges(tt.events); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getCutChanges() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,12 +1,23 @@
 // Package orch_delegators_exporter implements a Livepeer orchestrator delegators exporter that
 // fetches data from the Livepeer subgraph GraphQL API endpoint and exposes information about
-// the orchestrator's delegators via Prometheus metrics.
+// the orchestrator's delegators via Prometheus metrics. It compares successive snapshots of the
+// delegators to track which delegators joined and left the orchestrator and how much LPT moved. It
+// also estimates the earnings of each delegator using the data of the orch_info_exporter and
+// orch_rewards_exporter.
 package orch_delegators_exporter
 
 import (
+	"encoding/json"
 	"fmt"
 	"livepeer-exporter/constants"
+	"livepeer-exporter/exporters/orch_info_exporter"
+	"livepeer-exporter/exporters/orch_rewards_exporter"
 	"livepeer-exporter/fetcher"
+	"livepeer-exporter/util"
+	"log"
+	"math"
+	"net/http"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
@@ -18,24 +29,37 @@ var (
 	delegatorsEndpoint = constants.LivePeerSubgraphEndpoint
 )
 
-// graphqlQuery represents the GraphQL query to fetch data from the GraphQL API.
+// graphqlQueryTemplate represents the GraphQL query to fetch a page of delegators from the GraphQL API.
+// The delegators are ordered by ID so that the last ID of a page can be used as the cursor of the next page.
 const graphqlQueryTemplate = `
 {
-	delegators(where: {delegate: "%s"}) {
+	delegators(where: {delegate: "%s", id_gt: "%s"}, orderBy: id, orderDirection: asc, first: %d) {
 		id
 		startRound
 		bondedAmount
 		fees
+		principal
+		unbonded
+		withdrawnFees
+		lastClaimRound {
+			id
+		}
 	}
 }
 `
 
 // delegatorsResponse represents the structure of the delegators field contained in the GraphQL API response.
 type delegator struct {
-	ID           string
-	StartRound   string
-	BondedAmount string
-	Fees         string
+	ID             string
+	StartRound     string
+	BondedAmount   string
+	Fees           string
+	Principal      string
+	Unbonded       string
+	WithdrawnFees  string
+	LastClaimRound struct {
+		ID string
+	}
 }
 
 // delegatorsResponse represents the structure of the GraphQL API response.
@@ -46,24 +70,62 @@ type delegatorsResponse struct {
 	Data struct {
 		Delegators []delegator
 	}
+
+	// Whether the last fetch returned all delegators. When a fetch fails, the previous delegators are kept.
+	Complete bool
+}
+
+// DelegatorEarnings represents the estimated earnings of a delegator.
+type DelegatorEarnings struct {
+	Address        string  `json:"address"`        // The delegator address.
+	BondedAmount   float64 `json:"bondedAmount"`   // The amount of LPT bonded by the delegator.
+	Principal      float64 `json:"principal"`      // The amount of LPT bonded by the delegator, excluding rewards.
+	RewardsLPT     float64 `json:"rewardsLPT"`     // The estimated LPT rewards earned by the delegator.
+	FeesETH        float64 `json:"feesETH"`        // The ETH fees earned by the delegator.
+	ThirtyDayAPR   float64 `json:"thirtyDayAPR"`   // The estimated APR based on the rewards of the last 30 days.
+	LastClaimRound float64 `json:"lastClaimRound"` // The last round in which the delegator claimed its earnings.
 }
 
 // OrchDelegatorsExporter fetches data from the API and exposes orchestrator's delegators metrics via Prometheus.
 type OrchDelegatorsExporter struct {
 	// Metrics.
-	BondedAmount   *prometheus.GaugeVec
-	StartRound     *prometheus.GaugeVec
-	DelegatorCount prometheus.Gauge
-	CollectedFees  *prometheus.GaugeVec
+	BondedAmount            *prometheus.GaugeVec
+	StartRound              *prometheus.GaugeVec
+	DelegatorCount          prometheus.Gauge
+	ZeroStakeDelegatorCount prometheus.Gauge
+	CollectedFees           *prometheus.GaugeVec
+	DelegatorsGained        prometheus.Counter
+	DelegatorsLost          prometheus.Counter
+	StakeInflow             prometheus.Counter
+	StakeOutflow            prometheus.Counter
+	Principal               *prometheus.GaugeVec
+	Unbonded                *prometheus.GaugeVec
+	WithdrawnFees           *prometheus.GaugeVec
+	LastClaimRound          *prometheus.GaugeVec
+	TopTenStakeShare        prometheus.Gauge
+	StakeGini               prometheus.Gauge
+	MedianStake             prometheus.Gauge
+	SelfStake               prometheus.Gauge
+	ThirdPartyStake         prometheus.Gauge
+	RewardEarnings          *prometheus.GaugeVec
+	FeeEarnings             *prometheus.GaugeVec
+	ThirtyDayAPR            *prometheus.GaugeVec
 
 	// Config settings.
-	fetchInterval              time.Duration // How often to fetch data.
-	updateInterval             time.Duration // How often to update metrics.
-	orchDelegatorsEndpoint     string        // The endpoint to fetch data from.
-	orchDelegatorsGraphqlQuery string        // The GraphQL query to fetch data from the GraphQL API.
+	orchAddress            string        // The orchestrator address.
+	orchAddressSecondary   string        // The secondary orchestrator address.
+	fetchInterval          time.Duration // How often to fetch data.
+	updateInterval         time.Duration // How often to update metrics.
+	orchDelegatorsEndpoint string        // The endpoint to fetch data from.
 
 	// Data.
 	orchDelegators *delegatorsResponse // The data returned by the API.
+	bondedAmounts  map[string]float64  // The bonded amounts of the previous snapshot keyed by delegator address.
+	earnings       []DelegatorEarnings // The estimated earnings of each delegator.
+
+	// Data sources.
+	orchInfoExporter    *orch_info_exporter.OrchInfoExporter       // Provides the orchestrator's stake and cuts.
+	orchRewardsExporter *orch_rewards_exporter.OrchRewardsExporter // Provides the orchestrator's rewards.
 
 	// Fetchers.
 	orchDelegatorsFetcher fetcher.Fetcher
@@ -98,6 +160,115 @@ func (m *OrchDelegatorsExporter) initMetrics() {
 			Help: "The total number of delegators that are staked with the orchestrator.",
 		},
 	)
+	m.ZeroStakeDelegatorCount = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_delegator_zero_stake_count",
+			Help: "The number of delegators that are delegated to the orchestrator without any bonded LPT.",
+		},
+	)
+	m.DelegatorsGained = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "livepeer_orch_delegators_gained_total",
+			Help: "The number of delegators that started staking with the orchestrator since the exporter started.",
+		},
+	)
+	m.DelegatorsLost = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "livepeer_orch_delegators_lost_total",
+			Help: "The number of delegators that stopped staking with the orchestrator since the exporter started.",
+		},
+	)
+	m.StakeInflow = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "livepeer_orch_delegator_stake_inflow_total",
+			Help: "The amount of LPT by which the delegators' bonded amounts increased since the exporter started.",
+		},
+	)
+	m.StakeOutflow = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "livepeer_orch_delegator_stake_outflow_total",
+			Help: "The amount of LPT by which the delegators' bonded amounts decreased since the exporter started.",
+		},
+	)
+	m.Principal = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_delegator_principal",
+			Help: "The amount of LPT bonded by each delegator, excluding the claimed rewards.",
+		},
+		[]string{"id"},
+	)
+	m.Unbonded = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_delegator_unbonded_amount",
+			Help: "The total amount of LPT unbonded by each delegator.",
+		},
+		[]string{"id"},
+	)
+	m.WithdrawnFees = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_delegator_withdrawn_fees",
+			Help: "The total amount of ETH fees withdrawn by each delegator.",
+		},
+		[]string{"id"},
+	)
+	m.LastClaimRound = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_delegator_last_claim_round",
+			Help: "The last round in which each delegator claimed its earnings.",
+		},
+		[]string{"id"},
+	)
+	m.TopTenStakeShare = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_delegator_top_ten_stake_share",
+			Help: "The proportion of the delegated stake held by the ten largest delegators.",
+		},
+	)
+	m.StakeGini = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_delegator_stake_gini",
+			Help: "The Gini coefficient of the delegators' bonded amounts.",
+		},
+	)
+	m.MedianStake = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_delegator_median_stake",
+			Help: "The median bonded amount of the delegators.",
+		},
+	)
+	m.SelfStake = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_delegator_self_stake",
+			Help: "The amount of LPT bonded by the orchestrator's own addresses.",
+		},
+	)
+	m.ThirdPartyStake = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_delegator_third_party_stake",
+			Help: "The amount of LPT bonded by delegators other than the orchestrator's own addresses.",
+		},
+	)
+	m.RewardEarnings = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_delegator_reward_earnings",
+			Help: "The estimated amount of LPT rewards earned by each delegator.",
+		},
+		[]string{"id"},
+	)
+	m.FeeEarnings = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_delegator_fee_earnings",
+			Help: "The amount of ETH fees earned by each delegator.",
+		},
+		[]string{"id"},
+	)
+	m.ThirtyDayAPR = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_delegator_thirty_day_apr",
+			Help: "The estimated LPT reward APR of each delegator based on the rewards of the last 30 days.",
+		},
+		[]string{"id"},
+	)
 }
 
 // registerMetrics registers the orchestrator delegators metrics with Prometheus.
@@ -106,35 +277,264 @@ func (m *OrchDelegatorsExporter) registerMetrics() {
 		m.BondedAmount,
 		m.StartRound,
 		m.DelegatorCount,
+		m.ZeroStakeDelegatorCount,
 		m.CollectedFees,
+		m.DelegatorsGained,
+		m.DelegatorsLost,
+		m.StakeInflow,
+		m.StakeOutflow,
+		m.Principal,
+		m.Unbonded,
+		m.WithdrawnFees,
+		m.LastClaimRound,
+		m.TopTenStakeShare,
+		m.StakeGini,
+		m.MedianStake,
+		m.SelfStake,
+		m.ThirdPartyStake,
+		m.RewardEarnings,
+		m.FeeEarnings,
+		m.ThirtyDayAPR,
 	)
 }
 
+// getThirtyDayRewards returns the LPT rewards claimed by the orchestrator in the last 30 days.
+func (m *OrchDelegatorsExporter) getThirtyDayRewards() float64 {
+	thirtyDaysAgo := time.Now().AddDate(0, -1, 0)
+	var rewards float64
+	for _, reward := range m.orchRewardsExporter.Rewards() {
+		if !reward.Timestamp.Before(thirtyDaysAgo) {
+			rewards += reward.RewardTokens
+		}
+	}
+	return rewards
+}
+
+// estimateEarnings estimates the earnings of the given delegator. The LPT rewards are the bonded
+// amount plus the unbonded amount minus the principal. The APR is annualized from the rewards of the
+// last 30 days, where the delegator receives its share of the rewards after the reward cut and the
+// orchestrator additionally keeps the reward cut.
+func (m *OrchDelegatorsExporter) estimateEarnings(delegator delegator, orchInfo orch_info_exporter.OrchInfo, thirtyDayRewards float64) DelegatorEarnings {
+	var bondedAmount, principal, unbonded, fees, withdrawnFees, lastClaimRound float64
+	util.SetFloatFromStr(&bondedAmount, delegator.BondedAmount)
+	util.SetFloatFromStr(&principal, delegator.Principal)
+	util.SetFloatFromStr(&unbonded, delegator.Unbonded)
+	util.SetFloatFromStr(&fees, delegator.Fees)
+	util.SetFloatFromStr(&withdrawnFees, delegator.WithdrawnFees)
+	util.SetFloatFromStr(&lastClaimRound, delegator.LastClaimRound.ID)
+
+	earnings := DelegatorEarnings{
+		Address:        delegator.ID,
+		BondedAmount:   bondedAmount,
+		Principal:      principal,
+		RewardsLPT:     math.Max(bondedAmount+unbonded-principal, 0),
+		FeesETH:        fees + withdrawnFees,
+		LastClaimRound: lastClaimRound,
+	}
+
+	if bondedAmount > 0 && orchInfo.TotalStake > 0 {
+		thirtyDayEarnings := thirtyDayRewards * (1 - orchInfo.RewardCut) * bondedAmount / orchInfo.TotalStake
+		if delegator.ID == m.orchAddress {
+			thirtyDayEarnings += thirtyDayRewards * orchInfo.RewardCut
+		}
+		earnings.ThirtyDayAPR = thirtyDayEarnings / bondedAmount * 12
+	}
+	return earnings
+}
+
+// updateEarningsMetrics estimates the earnings of each delegator and updates the earnings metrics.
+func (m *OrchDelegatorsExporter) updateEarningsMetrics() {
+	orchInfo := m.orchInfoExporter.OrchInfo()
+	thirtyDayRewards := m.getThirtyDayRewards()
+
+	earnings := make([]DelegatorEarnings, 0, len(m.orchDelegators.Data.Delegators))
+	for _, delegator := range m.orchDelegators.Data.Delegators {
+		delegatorEarnings := m.estimateEarnings(delegator, orchInfo, thirtyDayRewards)
+		m.RewardEarnings.WithLabelValues(delegator.ID).Set(delegatorEarnings.RewardsLPT)
+		m.FeeEarnings.WithLabelValues(delegator.ID).Set(delegatorEarnings.FeesETH)
+		m.ThirtyDayAPR.WithLabelValues(delegator.ID).Set(delegatorEarnings.ThirtyDayAPR)
+		earnings = append(earnings, delegatorEarnings)
+	}
+
+	sort.SliceStable(earnings, func(i, j int) bool {
+		return earnings[i].BondedAmount > earnings[j].BondedAmount
+	})
+	m.earnings = earnings
+}
+
+// isSelfStake returns whether the given delegator is one of the orchestrator's own addresses.
+func (m *OrchDelegatorsExporter) isSelfStake(id string) bool {
+	return id == m.orchAddress || (m.orchAddressSecondary != "" && id == m.orchAddressSecondary)
+}
+
+// updateConcentrationMetrics updates the stake concentration metrics with the given bonded amounts.
+func (m *OrchDelegatorsExporter) updateConcentrationMetrics(bondedAmounts map[string]float64) {
+	var stakes []float64
+	var totalStake, selfStake float64
+	for id, bondedAmount := range bondedAmounts {
+		if bondedAmount <= 0 {
+			continue
+		}
+		stakes = append(stakes, bondedAmount)
+		totalStake += bondedAmount
+		if m.isSelfStake(id) {
+			selfStake += bondedAmount
+		}
+	}
+
+	// Calculate the share of the ten largest delegators.
+	sort.Sort(sort.Reverse(sort.Float64Slice(stakes)))
+	var topTenStake float64
+	for i := 0; i < len(stakes) && i < 10; i++ {
+		topTenStake += stakes[i]
+	}
+	var topTenStakeShare float64
+	if totalStake > 0 {
+		topTenStakeShare = topTenStake / totalStake
+	}
+
+	m.TopTenStakeShare.Set(topTenStakeShare)
+	m.StakeGini.Set(util.Gini(stakes))
+	m.MedianStake.Set(util.Median(stakes))
+	m.SelfStake.Set(selfStake)
+	m.ThirdPartyStake.Set(totalStake - selfStake)
+}
+
+// updateLifecycleMetrics compares the given bonded amounts with those of the previous snapshot and
+// updates the delegator lifecycle metrics accordingly. The first snapshot is only used as a baseline.
+// It also removes the series of delegators that are no longer delegated to the orchestrator. It must
+// only be called with complete snapshots, since missing delegators are counted as lost.
+func (m *OrchDelegatorsExporter) updateLifecycleMetrics(bondedAmounts map[string]float64) {
+	if m.bondedAmounts != nil {
+		for id, bondedAmount := range bondedAmounts {
+			previousAmount := m.bondedAmounts[id]
+			if previousAmount <= 0 && bondedAmount > 0 {
+				m.DelegatorsGained.Inc()
+			} else if previousAmount > 0 && bondedAmount <= 0 {
+				m.DelegatorsLost.Inc()
+			}
+			if bondedAmount > previousAmount {
+				m.StakeInflow.Add(bondedAmount - previousAmount)
+			} else if bondedAmount < previousAmount {
+				m.StakeOutflow.Add(previousAmount - bondedAmount)
+			}
+		}
+	}
+
+	// Handle the delegators that moved to another orchestrator.
+	for id, previousAmount := range m.bondedAmounts {
+		if _, ok := bondedAmounts[id]; ok {
+			continue
+		}
+		if previousAmount > 0 {
+			m.DelegatorsLost.Inc()
+			m.StakeOutflow.Add(previousAmount)
+		}
+		m.BondedAmount.DeleteLabelValues(id)
+		m.StartRound.DeleteLabelValues(id)
+		m.CollectedFees.DeleteLabelValues(id)
+		m.Principal.DeleteLabelValues(id)
+		m.Unbonded.DeleteLabelValues(id)
+		m.WithdrawnFees.DeleteLabelValues(id)
+		m.LastClaimRound.DeleteLabelValues(id)
+		m.RewardEarnings.DeleteLabelValues(id)
+		m.FeeEarnings.DeleteLabelValues(id)
+		m.ThirtyDayAPR.DeleteLabelValues(id)
+	}
+
+	m.bondedAmounts = bondedAmounts
+}
+
 // updateMetrics updates the metrics with the data fetched from the stonk.rocks orchestrator API.
 func (m *OrchDelegatorsExporter) updateMetrics() {
-	// Set the DelegatorCount metric by counting the length of the Delegators slice.
-	m.DelegatorCount.Set(float64(len(m.orchDelegators.Data.Delegators)))
+	// Skip empty responses since the orchestrator is always delegated to itself.
+	if len(m.orchDelegators.Data.Delegators) == 0 {
+		return
+	}
 
 	// Set the BondedAmount and StartRound metrics for each delegator.
+	var delegatorCount, zeroStakeDelegatorCount float64
+	bondedAmounts := make(map[string]float64, len(m.orchDelegators.Data.Delegators))
 	for _, delegator := range m.orchDelegators.Data.Delegators {
 		bondedAmount, _ := strconv.ParseFloat(delegator.BondedAmount, 64)
 		startRound, _ := strconv.ParseFloat(delegator.StartRound, 64)
 		feesCollected, _ := strconv.ParseFloat(delegator.Fees, 64)
+		principal, _ := strconv.ParseFloat(delegator.Principal, 64)
+		unbonded, _ := strconv.ParseFloat(delegator.Unbonded, 64)
+		withdrawnFees, _ := strconv.ParseFloat(delegator.WithdrawnFees, 64)
+		lastClaimRound, _ := strconv.ParseFloat(delegator.LastClaimRound.ID, 64)
 
 		m.BondedAmount.WithLabelValues(delegator.ID).Set(bondedAmount)
 		m.StartRound.WithLabelValues(delegator.ID).Set(startRound)
 		m.CollectedFees.WithLabelValues(delegator.ID).Set(feesCollected)
+		m.Principal.WithLabelValues(delegator.ID).Set(principal)
+		m.Unbonded.WithLabelValues(delegator.ID).Set(unbonded)
+		m.WithdrawnFees.WithLabelValues(delegator.ID).Set(withdrawnFees)
+		m.LastClaimRound.WithLabelValues(delegator.ID).Set(lastClaimRound)
+
+		bondedAmounts[delegator.ID] = bondedAmount
+		if bondedAmount > 0 {
+			delegatorCount++
+		} else {
+			zeroStakeDelegatorCount++
+		}
+	}
+
+	// Set the delegator count metrics. Delegators without bonded LPT are counted separately.
+	m.DelegatorCount.Set(delegatorCount)
+	m.ZeroStakeDelegatorCount.Set(zeroStakeDelegatorCount)
+
+	// Update the stake concentration, earnings and delegator lifecycle metrics. The snapshot is not
+	// compared when the last fetch failed.
+	m.updateConcentrationMetrics(bondedAmounts)
+	m.updateEarningsMetrics()
+	if m.orchDelegators.Complete {
+		m.updateLifecycleMetrics(bondedAmounts)
 	}
 }
 
+// fetchData fetches all delegators of the orchestrator page by page. The delegators are only replaced
+// when all pages were fetched.
+func (m *OrchDelegatorsExporter) fetchData() {
+	var delegators []delegator
+	for {
+		var cursor string
+		if len(delegators) > 0 {
+			cursor = delegators[len(delegators)-1].ID
+		}
+		page := &delegatorsResponse{}
+		pageFetcher := m.orchDelegatorsFetcher
+		pageFetcher.Data = page
+		if err := pageFetcher.FetchGraphQLData(fmt.Sprintf(graphqlQueryTemplate, m.orchAddress, cursor, constants.SubgraphPageSize)); err != nil {
+			log.Printf("Error fetching delegators after '%s': %v", cursor, err)
+			m.orchDelegators.Mutex.Lock()
+			m.orchDelegators.Complete = false
+			m.orchDelegators.Mutex.Unlock()
+			return
+		}
+		delegators = append(delegators, page.Data.Delegators...)
+		if len(page.Data.Delegators) < constants.SubgraphPageSize {
+			break
+		}
+	}
+
+	m.orchDelegators.Mutex.Lock()
+	m.orchDelegators.Data.Delegators = delegators
+	m.orchDelegators.Complete = true
+	m.orchDelegators.Mutex.Unlock()
+}
+
 // NewOrchDelegatorsExporter creates a new OrchDelegatorsExporter.
-func NewOrchDelegatorsExporter(orchAddress string, fetchInterval time.Duration, updateInterval time.Duration) *OrchDelegatorsExporter {
+func NewOrchDelegatorsExporter(orchAddress string, fetchInterval time.Duration, updateInterval time.Duration, orchAddrSecondary string, orchInfoExporter *orch_info_exporter.OrchInfoExporter, orchRewardsExporter *orch_rewards_exporter.OrchRewardsExporter) *OrchDelegatorsExporter {
 	exporter := &OrchDelegatorsExporter{
-		fetchInterval:              fetchInterval,
-		updateInterval:             updateInterval,
-		orchDelegatorsEndpoint:     delegatorsEndpoint,
-		orchDelegatorsGraphqlQuery: fmt.Sprintf(graphqlQueryTemplate, orchAddress),
-		orchDelegators:             &delegatorsResponse{},
+		orchInfoExporter:       orchInfoExporter,
+		orchRewardsExporter:    orchRewardsExporter,
+		orchAddress:            orchAddress,
+		orchAddressSecondary:   orchAddrSecondary,
+		fetchInterval:          fetchInterval,
+		updateInterval:         updateInterval,
+		orchDelegatorsEndpoint: delegatorsEndpoint,
+		orchDelegators:         &delegatorsResponse{},
 	}
 
 	// Create request headers.
@@ -142,10 +542,9 @@ func NewOrchDelegatorsExporter(orchAddress string, fetchInterval time.Duration,
 		"X-Device-ID": {fmt.Sprintf(constants.ClientIDTemplate, orchAddress)},
 	}
 
-	// Initialize fetcher.
+	// Initialize fetcher. The target of each page is set when fetching.
 	exporter.orchDelegatorsFetcher = fetcher.Fetcher{
 		URL:     exporter.orchDelegatorsEndpoint,
-		Data:    &exporter.orchDelegators,
 		Headers: headers,
 	}
 
@@ -156,11 +555,29 @@ func NewOrchDelegatorsExporter(orchAddress string, fetchInterval time.Duration,
 	return exporter
 }
 
+// Earnings returns the estimated earnings of each delegator sorted by bonded amount.
+func (m *OrchDelegatorsExporter) Earnings() []DelegatorEarnings {
+	m.orchDelegators.Mutex.Lock()
+	defer m.orchDelegators.Mutex.Unlock()
+	return append([]DelegatorEarnings(nil), m.earnings...)
+}
+
+// ServeEarnings serves the estimated earnings of each delegator as JSON.
+func (m *OrchDelegatorsExporter) ServeEarnings(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*") // Allow the earnings to be embedded in websites.
+	if err := json.NewEncoder(w).Encode(m.Earnings()); err != nil {
+		log.Printf("Error encoding delegator earnings: %v", err)
+	}
+}
+
 // Start starts the OrchDelegatorsExporter.
 func (m *OrchDelegatorsExporter) Start() {
 	// Fetch initial data and update metrics.
-	m.orchDelegatorsFetcher.FetchGraphQLData(m.orchDelegatorsGraphqlQuery)
+	m.fetchData()
+	m.orchDelegators.Mutex.Lock()
 	m.updateMetrics()
+	m.orchDelegators.Mutex.Unlock()
 
 	// Start fetcher in a goroutine.
 	go func() {
@@ -168,9 +585,7 @@ func (m *OrchDelegatorsExporter) Start() {
 		defer ticker.Stop()
 
 		for range ticker.C {
-			m.orchDelegators.Mutex.Lock()
-			m.orchDelegatorsFetcher.FetchGraphQLData(m.orchDelegatorsGraphqlQuery)
-			m.orchDelegators.Mutex.Unlock()
+			m.fetchData()
 		}
 	}()
 
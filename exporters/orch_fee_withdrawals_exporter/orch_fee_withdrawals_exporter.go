@@ -0,0 +1,421 @@
+// Package orch_fee_withdrawals_exporter implements a Livepeer orchestrator fee withdrawals exporter that
+// fetches the fee withdrawals and the unwithdrawn fees of the orchestrator from the Livepeer subgraph GraphQL
+// API endpoint, together with the gas costs of the withdrawals from their transaction receipts, and exposes
+// them via Prometheus metrics.
+package orch_fee_withdrawals_exporter
+
+import (
+	"fmt"
+	"livepeer-exporter/constants"
+	"livepeer-exporter/fetcher"
+	"livepeer-exporter/receipts"
+	"livepeer-exporter/util"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	withdrawFeesEventsEndpoint = constants.LivePeerSubgraphEndpoint
+)
+
+// delegatorQueryTemplate represents the GraphQL query to fetch the orchestrator's delegator from the GraphQL API.
+const delegatorQueryTemplate = `
+{
+	delegator(id: "%s") {
+		bondedAmount
+		fees
+		lastClaimRound {
+			id
+		}
+	}
+}
+`
+
+// withdrawFeesEventsQueryTemplate represents the GraphQL query to fetch a page of withdraw fees events from the
+// GraphQL API. The events are ordered by ID so that the last ID of a page can be used as the cursor of the next page.
+const withdrawFeesEventsQueryTemplate = `
+{
+	withdrawFeesEvents(where: {delegator: "%s", id_gt: "%s"}, orderBy: id, orderDirection: asc, first: %d) {
+		id
+		transaction {
+			blockNumber
+			timestamp
+			id
+		}
+		round {
+			id
+		}
+		amount
+	}
+}
+`
+
+// poolsQueryTemplate represents the GraphQL query to fetch a page of pools from the GraphQL API. The pools
+// are paginated by ID like the withdraw fees events.
+const poolsQueryTemplate = `
+{
+	pools(where: {delegate: "%s", id_gt: "%s"}, orderBy: id, orderDirection: asc, first: %d) {
+		id
+		fees
+		totalStake
+		feeShare
+		round {
+			id
+		}
+	}
+}
+`
+
+// withdrawFeesEvent represents the structure of the withdrawFeesEvents field contained in the GraphQL API response.
+type withdrawFeesEvent struct {
+	ID          string
+	Transaction struct {
+		BlockNumber string
+		Timestamp   int
+		ID          string
+	}
+	Round struct {
+		ID string
+	}
+	Amount string
+}
+
+// pool represents the structure of the pools field contained in the GraphQL API response.
+type pool struct {
+	ID         string
+	Fees       string
+	TotalStake string
+	FeeShare   string
+	Round      struct {
+		ID string
+	}
+}
+
+// withdrawFeesEventsResponse represents the structure of the GraphQL API response.
+type withdrawFeesEventsResponse struct {
+	sync.Mutex
+
+	// Response data.
+	Data struct {
+		WithdrawFeesEvents []withdrawFeesEvent
+		Delegator          struct {
+			BondedAmount   string
+			Fees           string
+			LastClaimRound struct {
+				ID string
+			}
+		}
+		Pools []pool
+	}
+
+	// Whether all withdraw fees events and pools were fetched. The previous data is kept when a fetch fails.
+	Complete bool
+}
+
+// feeWithdrawal represents a parsed withdraw fees event.
+type feeWithdrawal struct {
+	ID        string    // The transaction hash of the withdrawal.
+	Timestamp time.Time // The block time of the withdrawal.
+	Round     float64   // The round in which the fees were withdrawn.
+	Amount    float64   // The amount of ETH withdrawn.
+}
+
+// parseFeeWithdrawal parses a withdrawFeesEvent into a feeWithdrawal.
+func parseFeeWithdrawal(event withdrawFeesEvent) feeWithdrawal {
+	withdrawal := feeWithdrawal{
+		ID:        event.Transaction.ID,
+		Timestamp: time.Unix(int64(event.Transaction.Timestamp), 0),
+	}
+	util.SetFloatFromStr(&withdrawal.Round, event.Round.ID)
+	util.SetFloatFromStr(&withdrawal.Amount, event.Amount)
+	return withdrawal
+}
+
+// getUnclaimedFees estimates the fees the orchestrator earned in the rounds after its last claim round. The
+// orchestrator keeps the fee cut and receives a share of the remainder proportional to its bonded stake.
+func getUnclaimedFees(pools []pool, lastClaimRound int, bondedAmount float64) float64 {
+	var unclaimedFees float64
+	for _, pool := range pools {
+		round, _ := strconv.Atoi(pool.Round.ID)
+		if round <= lastClaimRound {
+			continue
+		}
+		var fees, totalStake, feeShare float64
+		util.SetFloatFromStr(&fees, pool.Fees)
+		util.SetFloatFromStr(&totalStake, pool.TotalStake)
+		util.SetFloatFromStr(&feeShare, pool.FeeShare)
+		feeShare *= 1e-6
+
+		unclaimedFees += fees * (1 - feeShare)
+		if totalStake > 0 {
+			unclaimedFees += fees * feeShare * bondedAmount / totalStake
+		}
+	}
+	return unclaimedFees
+}
+
+// OrchFeeWithdrawalsExporter fetches data from the API and exposes the orchestrator's fee withdrawals via Prometheus.
+type OrchFeeWithdrawalsExporter struct {
+	// Metrics.
+	WithdrawalAmount    *prometheus.GaugeVec
+	WithdrawalGasCost   *prometheus.GaugeVec
+	WithdrawalBlockTime *prometheus.GaugeVec
+	Withdrawals         *prometheus.GaugeVec
+	LastWithdrawalTime  prometheus.Gauge
+	PendingFees         prometheus.Gauge
+
+	// Config settings.
+	orchAddress                string        // The orchestrator address.
+	fetchInterval              time.Duration // How often to fetch data.
+	updateInterval             time.Duration // How often to update metrics.
+	orchFeeWithdrawalsEndpoint string        // The endpoint to fetch data from.
+
+	// Data.
+	orchFeeWithdrawals *withdrawFeesEventsResponse // The data returned by the API.
+
+	// Fetchers.
+	orchFeeWithdrawalsFetcher fetcher.Fetcher
+
+	// Data sources.
+	receiptProvider *receipts.ReceiptProvider // Provides the gas costs of the withdrawals.
+}
+
+// initMetrics initializes the fee withdrawal metrics.
+func (m *OrchFeeWithdrawalsExporter) initMetrics() {
+	m.WithdrawalAmount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_fee_withdrawal_amount",
+			Help: "The amount of ETH fees withdrawn by each withdrawal transaction.",
+		},
+		[]string{"id"},
+	)
+	m.WithdrawalGasCost = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_fee_withdrawal_gas_cost",
+			Help: "The gas cost for each fee withdrawal transaction according to its receipt in Gwei.",
+		},
+		[]string{"id"},
+	)
+	m.WithdrawalBlockTime = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_fee_withdrawal_block_time",
+			Help: "The block time for each fee withdrawal transaction.",
+		},
+		[]string{"id"},
+	)
+	m.Withdrawals = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_fee_withdrawals",
+			Help: "The amount of ETH fees withdrawn by the orchestrator per period.",
+		},
+		[]string{"period"},
+	)
+	m.LastWithdrawalTime = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_last_fee_withdrawal_time",
+			Help: "The block time of the last fee withdrawal of the orchestrator.",
+		},
+	)
+	m.PendingFees = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_pending_fees",
+			Help: "The estimated amount of ETH fees the orchestrator earned but has not withdrawn yet.",
+		},
+	)
+}
+
+// registerMetrics registers the fee withdrawal metrics with Prometheus.
+func (m *OrchFeeWithdrawalsExporter) registerMetrics() {
+	prometheus.MustRegister(
+		m.WithdrawalAmount,
+		m.WithdrawalGasCost,
+		m.WithdrawalBlockTime,
+		m.Withdrawals,
+		m.LastWithdrawalTime,
+		m.PendingFees,
+	)
+}
+
+// updateMetrics updates the metrics with the data fetched from the Livepeer subgraph GraphQL API.
+func (m *OrchFeeWithdrawalsExporter) updateMetrics() {
+	// Wait until all withdrawals and pools were fetched so that the totals are not underestimated.
+	if !m.orchFeeWithdrawals.Complete {
+		return
+	}
+
+	// Set the metrics for each withdrawal.
+	var withdrawals []feeWithdrawal
+	var lastWithdrawalTime time.Time
+	for _, event := range m.orchFeeWithdrawals.Data.WithdrawFeesEvents {
+		withdrawal := parseFeeWithdrawal(event)
+		withdrawals = append(withdrawals, withdrawal)
+
+		m.WithdrawalAmount.WithLabelValues(withdrawal.ID).Set(withdrawal.Amount)
+		if receipt, ok := m.receiptProvider.GetReceipt(withdrawal.ID); ok {
+			m.WithdrawalGasCost.WithLabelValues(withdrawal.ID).Set(receipt.GasCost() * 1e9) // Expressed in Gwei.
+		}
+		m.WithdrawalBlockTime.WithLabelValues(withdrawal.ID).Set(float64(withdrawal.Timestamp.Unix()) * 1000) // Grafana expects milliseconds.
+		if withdrawal.Timestamp.After(lastWithdrawalTime) {
+			lastWithdrawalTime = withdrawal.Timestamp
+		}
+	}
+	if !lastWithdrawalTime.IsZero() {
+		m.LastWithdrawalTime.Set(float64(lastWithdrawalTime.Unix()) * 1000) // Grafana expects milliseconds.
+	}
+
+	// Calculate the withdrawn fees per period.
+	for _, period := range util.GetPeriods(time.Now()) {
+		var amount float64
+		for _, withdrawal := range withdrawals {
+			if !withdrawal.Timestamp.Before(period.Start) {
+				amount += withdrawal.Amount
+			}
+		}
+		m.Withdrawals.WithLabelValues(period.Name).Set(amount)
+	}
+
+	// Estimate the pending fees from the claimed but unwithdrawn fees and the fees earned since the last claim.
+	delegator := m.orchFeeWithdrawals.Data.Delegator
+	var claimedFees, bondedAmount, lastClaimRound float64
+	util.SetFloatFromStr(&claimedFees, delegator.Fees)
+	util.SetFloatFromStr(&bondedAmount, delegator.BondedAmount)
+	util.SetFloatFromStr(&lastClaimRound, delegator.LastClaimRound.ID)
+	m.PendingFees.Set(claimedFees + getUnclaimedFees(m.orchFeeWithdrawals.Data.Pools, int(lastClaimRound), bondedAmount))
+}
+
+// NewOrchFeeWithdrawalsExporter creates a new OrchFeeWithdrawalsExporter.
+func NewOrchFeeWithdrawalsExporter(orchAddress string, fetchInterval time.Duration, updateInterval time.Duration, receiptProvider *receipts.ReceiptProvider) *OrchFeeWithdrawalsExporter {
+	exporter := &OrchFeeWithdrawalsExporter{
+		orchAddress:                orchAddress,
+		fetchInterval:              fetchInterval,
+		updateInterval:             updateInterval,
+		orchFeeWithdrawalsEndpoint: withdrawFeesEventsEndpoint,
+		orchFeeWithdrawals:         &withdrawFeesEventsResponse{},
+		receiptProvider:            receiptProvider,
+	}
+
+	// Create request headers.
+	headers := map[string][]string{
+		"X-Device-ID": {fmt.Sprintf(constants.ClientIDTemplate, orchAddress)},
+	}
+
+	// Initialize fetcher. The target of each query is set when fetching.
+	exporter.orchFeeWithdrawalsFetcher = fetcher.Fetcher{
+		URL:     exporter.orchFeeWithdrawalsEndpoint,
+		Headers: headers,
+	}
+
+	// Initialize metrics.
+	exporter.initMetrics()
+	exporter.registerMetrics()
+
+	return exporter
+}
+
+// fetchData fetches the orchestrator's delegator and all of its withdraw fees events and pools, page by page,
+// from the Livepeer subgraph GraphQL API. The previously fetched data is only replaced when all queries
+// succeeded.
+func (m *OrchFeeWithdrawalsExporter) fetchData() error {
+	response := &withdrawFeesEventsResponse{}
+	responseFetcher := m.orchFeeWithdrawalsFetcher
+	responseFetcher.Data = response
+	if err := responseFetcher.FetchGraphQLData(fmt.Sprintf(delegatorQueryTemplate, m.orchAddress)); err != nil {
+		return fmt.Errorf("error fetching delegator: %w", err)
+	}
+
+	var events []withdrawFeesEvent
+	for {
+		var cursor string
+		if len(events) > 0 {
+			cursor = events[len(events)-1].ID
+		}
+		page := &withdrawFeesEventsResponse{}
+		pageFetcher := m.orchFeeWithdrawalsFetcher
+		pageFetcher.Data = page
+		if err := pageFetcher.FetchGraphQLData(fmt.Sprintf(withdrawFeesEventsQueryTemplate, m.orchAddress, cursor, constants.SubgraphPageSize)); err != nil {
+			return fmt.Errorf("error fetching withdraw fees events after '%s': %w", cursor, err)
+		}
+		events = append(events, page.Data.WithdrawFeesEvents...)
+		if len(page.Data.WithdrawFeesEvents) < constants.SubgraphPageSize {
+			break
+		}
+	}
+	response.Data.WithdrawFeesEvents = events
+
+	var pools []pool
+	for {
+		var cursor string
+		if len(pools) > 0 {
+			cursor = pools[len(pools)-1].ID
+		}
+		page := &withdrawFeesEventsResponse{}
+		pageFetcher := m.orchFeeWithdrawalsFetcher
+		pageFetcher.Data = page
+		if err := pageFetcher.FetchGraphQLData(fmt.Sprintf(poolsQueryTemplate, m.orchAddress, cursor, constants.SubgraphPageSize)); err != nil {
+			return fmt.Errorf("error fetching pools after '%s': %w", cursor, err)
+		}
+		pools = append(pools, page.Data.Pools...)
+		if len(page.Data.Pools) < constants.SubgraphPageSize {
+			break
+		}
+	}
+	response.Data.Pools = pools
+
+	m.orchFeeWithdrawals.Mutex.Lock()
+	defer m.orchFeeWithdrawals.Mutex.Unlock()
+	m.orchFeeWithdrawals.Data = response.Data
+	m.orchFeeWithdrawals.Complete = true
+	return nil
+}
+
+// fetchReceipts fetches the receipts of the withdrawals that were not fetched before.
+func (m *OrchFeeWithdrawalsExporter) fetchReceipts() {
+	m.orchFeeWithdrawals.Mutex.Lock()
+	ids := make([]string, 0, len(m.orchFeeWithdrawals.Data.WithdrawFeesEvents))
+	for _, event := range m.orchFeeWithdrawals.Data.WithdrawFeesEvents {
+		ids = append(ids, event.Transaction.ID)
+	}
+	m.orchFeeWithdrawals.Mutex.Unlock()
+	m.receiptProvider.Fetch(ids)
+}
+
+// Start starts the OrchFeeWithdrawalsExporter.
+func (m *OrchFeeWithdrawalsExporter) Start() {
+	// Fetch initial data and update metrics.
+	if err := m.fetchData(); err != nil {
+		log.Printf("Error fetching fee withdrawals: %v", err)
+	}
+	m.fetchReceipts()
+	m.orchFeeWithdrawals.Mutex.Lock()
+	m.updateMetrics()
+	m.orchFeeWithdrawals.Mutex.Unlock()
+
+	// Start fetcher in a goroutine.
+	go func() {
+		ticker := time.NewTicker(m.fetchInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := m.fetchData(); err != nil {
+				log.Printf("Error fetching fee withdrawals: %v", err)
+			}
+			m.fetchReceipts()
+		}
+	}()
+
+	// Start metrics updater in a goroutine.
+	go func() {
+		ticker := time.NewTicker(m.updateInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			m.orchFeeWithdrawals.Mutex.Lock()
+			m.updateMetrics()
+			m.orchFeeWithdrawals.Mutex.Unlock()
+		}
+	}()
+}
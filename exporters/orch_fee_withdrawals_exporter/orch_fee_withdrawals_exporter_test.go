@@ -0,0 +1,67 @@
+package orch_fee_withdrawals_exporter
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+// newPool returns a pool of the given round with the given fees, total stake and fee share in parts per
+// million.
+func newPool(round int, fees string, totalStake string, feeShare string) pool {
+	p := pool{Fees: fees, TotalStake: totalStake, FeeShare: feeShare}
+	p.Round.ID = strconv.Itoa(round)
+	return p
+}
+
+func TestGetUnclaimedFees(t *testing.T) {
+	tests := []struct {
+		name           string
+		pools          []pool
+		lastClaimRound int
+		bondedAmount   float64
+		want           float64
+	}{
+		{
+			name:           "no pools",
+			lastClaimRound: 10,
+			bondedAmount:   100,
+			want:           0,
+		},
+		{
+			name:           "claimed rounds are skipped",
+			pools:          []pool{newPool(9, "1", "1000", "500000"), newPool(10, "1", "1000", "500000")},
+			lastClaimRound: 10,
+			bondedAmount:   100,
+			want:           0,
+		},
+		{
+			name:           "fee cut and stake share",
+			pools:          []pool{newPool(11, "2", "1000", "750000")},
+			lastClaimRound: 10,
+			bondedAmount:   100,
+			want:           2*0.25 + 2*0.75*0.1,
+		},
+		{
+			name:           "multiple rounds",
+			pools:          []pool{newPool(12, "1", "500", "0"), newPool(11, "4", "1000", "1000000")},
+			lastClaimRound: 10,
+			bondedAmount:   250,
+			want:           1 + 4*0.25,
+		},
+		{
+			name:           "pool without stake",
+			pools:          []pool{newPool(11, "2", "0", "500000")},
+			lastClaimRound: 10,
+			bondedAmount:   100,
+			want:           1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getUnclaimedFees(tt.pools, tt.lastClaimRound, tt.bondedAmount); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("getUnclaimedFees() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+// Package orch_forecast_exporter implements a Livepeer orchestrator forecast exporter that projects the
+// fees and rewards of the next 30 days from the daily history of the orch_tickets_exporter and
+// orch_rewards_exporter and exposes the forecasts via Prometheus metrics.
+package orch_forecast_exporter
+
+import (
+	"livepeer-exporter/exporters/orch_rewards_exporter"
+	"livepeer-exporter/exporters/orch_tickets_exporter"
+	"livepeer-exporter/util"
+	"math"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// The supported forecast methods.
+const (
+	MethodMovingAverage = "moving_average" // Projects the average daily amount of the lookback window.
+	MethodLinearTrend   = "linear_trend"   // Projects the linear trend of the daily amounts of the lookback window.
+)
+
+const (
+	forecastDays = 30   // The number of days that are forecasted.
+	boundsZScore = 1.96 // The z-score of the 95% prediction interval.
+)
+
+// forecast represents the projected amount of the forecast window with its prediction interval.
+type forecast struct {
+	Expected   float64 // The projected amount.
+	LowerBound float64 // The lower bound of the prediction interval.
+	UpperBound float64 // The upper bound of the prediction interval.
+}
+
+// getForecast projects the total of the next forecastDays days from the given daily amounts using the
+// given method. The prediction interval assumes the daily deviations are independent.
+func getForecast(daily []float64, method string) forecast {
+	var expected, deviation float64
+	switch method {
+	case MethodLinearTrend:
+		intercept, slope := util.LinearRegression(daily)
+		residuals := make([]float64, len(daily))
+		for i, v := range daily {
+			residuals[i] = v - (intercept + slope*float64(i))
+		}
+		for day := len(daily); day < len(daily)+forecastDays; day++ {
+			expected += math.Max(intercept+slope*float64(day), 0)
+		}
+		deviation = util.StdDev(residuals)
+	default:
+		expected = util.Mean(daily) * forecastDays
+		deviation = util.StdDev(daily)
+	}
+
+	margin := boundsZScore * deviation * math.Sqrt(forecastDays)
+	return forecast{
+		Expected:   expected,
+		LowerBound: math.Max(expected-margin, 0),
+		UpperBound: expected + margin,
+	}
+}
+
+// OrchForecastExporter forecasts the orchestrator's fees and rewards and exposes them via Prometheus.
+type OrchForecastExporter struct {
+	// Metrics.
+	FeesForecast      prometheus.Gauge
+	FeesLowerBound    prometheus.Gauge
+	FeesUpperBound    prometheus.Gauge
+	RewardsForecast   prometheus.Gauge
+	RewardsLowerBound prometheus.Gauge
+	RewardsUpperBound prometheus.Gauge
+
+	// Config settings.
+	updateInterval time.Duration // How often to update metrics.
+	lookbackDays   int           // The number of days of history the forecasts are based on.
+	method         string        // The forecast method.
+
+	// Data sources.
+	orchTicketsExporter *orch_tickets_exporter.OrchTicketsExporter // Provides the orchestrator's fees.
+	orchRewardsExporter *orch_rewards_exporter.OrchRewardsExporter // Provides the orchestrator's rewards.
+}
+
+// initMetrics initializes the orchestrator forecast metrics.
+func (m *OrchForecastExporter) initMetrics() {
+	m.FeesForecast = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_forecast_fees_eth",
+			Help: "The forecasted ETH fees of the next 30 days.",
+		},
+	)
+	m.FeesLowerBound = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_forecast_fees_eth_lower_bound",
+			Help: "The lower bound of the 95% prediction interval of the ETH fees of the next 30 days.",
+		},
+	)
+	m.FeesUpperBound = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_forecast_fees_eth_upper_bound",
+			Help: "The upper bound of the 95% prediction interval of the ETH fees of the next 30 days.",
+		},
+	)
+	m.RewardsForecast = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_forecast_rewards_lpt",
+			Help: "The forecasted LPT rewards of the next 30 days.",
+		},
+	)
+	m.RewardsLowerBound = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_forecast_rewards_lpt_lower_bound",
+			Help: "The lower bound of the 95% prediction interval of the LPT rewards of the next 30 days.",
+		},
+	)
+	m.RewardsUpperBound = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_forecast_rewards_lpt_upper_bound",
+			Help: "The upper bound of the 95% prediction interval of the LPT rewards of the next 30 days.",
+		},
+	)
+}
+
+// registerMetrics registers the orchestrator forecast metrics with Prometheus.
+func (m *OrchForecastExporter) registerMetrics() {
+	prometheus.MustRegister(
+		m.FeesForecast,
+		m.FeesLowerBound,
+		m.FeesUpperBound,
+		m.RewardsForecast,
+		m.RewardsLowerBound,
+		m.RewardsUpperBound,
+	)
+}
+
+// getDayIndex returns the index of the UTC day of the given time in the window of the given number of days
+// starting at windowStart. It returns false if the time is outside of the window.
+func getDayIndex(timestamp time.Time, windowStart time.Time, days int) (int, bool) {
+	if timestamp.Before(windowStart) {
+		return 0, false
+	}
+	index := int(timestamp.Sub(windowStart) / (24 * time.Hour))
+	return index, index < days
+}
+
+// getDailyAmounts aggregates the given fees and rewards per UTC day for the completed days of the lookback
+// window. The current day is not included since it is not complete. The days are calculated in UTC so that
+// each day spans exactly 24 hours, regardless of daylight saving time changes in the local time zone.
+func getDailyAmounts(now time.Time, lookbackDays int, tickets []orch_tickets_exporter.WinningTicket, rewards []orch_rewards_exporter.Reward) (dailyFees []float64, dailyRewards []float64) {
+	windowEnd := now.UTC().Truncate(24 * time.Hour)
+	windowStart := windowEnd.Add(-time.Duration(lookbackDays) * 24 * time.Hour)
+	dailyFees = make([]float64, lookbackDays)
+	dailyRewards = make([]float64, lookbackDays)
+
+	for _, ticket := range tickets {
+		if index, ok := getDayIndex(ticket.Timestamp, windowStart, lookbackDays); ok {
+			dailyFees[index] += ticket.FaceValue
+		}
+	}
+	for _, reward := range rewards {
+		if index, ok := getDayIndex(reward.Timestamp, windowStart, lookbackDays); ok {
+			dailyRewards[index] += reward.RewardTokens
+		}
+	}
+	return dailyFees, dailyRewards
+}
+
+// updateMetrics updates the metrics with the data of the tickets and rewards exporters. Each forecast is
+// only updated once all winning tickets or reward events were fetched, since missing days would be
+// forecasted as days without earnings.
+func (m *OrchForecastExporter) updateMetrics() {
+	dailyFees, dailyRewards := getDailyAmounts(time.Now(), m.lookbackDays, m.orchTicketsExporter.Tickets(), m.orchRewardsExporter.Rewards())
+
+	if m.orchTicketsExporter.Complete() {
+		fees := getForecast(dailyFees, m.method)
+		m.FeesForecast.Set(fees.Expected)
+		m.FeesLowerBound.Set(fees.LowerBound)
+		m.FeesUpperBound.Set(fees.UpperBound)
+	}
+
+	if m.orchRewardsExporter.Complete() {
+		rewards := getForecast(dailyRewards, m.method)
+		m.RewardsForecast.Set(rewards.Expected)
+		m.RewardsLowerBound.Set(rewards.LowerBound)
+		m.RewardsUpperBound.Set(rewards.UpperBound)
+	}
+}
+
+// NewOrchForecastExporter creates a new OrchForecastExporter.
+func NewOrchForecastExporter(updateInterval time.Duration, lookbackDays int, method string, orchTicketsExporter *orch_tickets_exporter.OrchTicketsExporter, orchRewardsExporter *orch_rewards_exporter.OrchRewardsExporter) *OrchForecastExporter {
+	exporter := &OrchForecastExporter{
+		updateInterval:      updateInterval,
+		lookbackDays:        lookbackDays,
+		method:              method,
+		orchTicketsExporter: orchTicketsExporter,
+		orchRewardsExporter: orchRewardsExporter,
+	}
+
+	// Initialize metrics.
+	exporter.initMetrics()
+	exporter.registerMetrics()
+
+	return exporter
+}
+
+// Start starts the OrchForecastExporter.
+func (m *OrchForecastExporter) Start() {
+	// Update initial metrics.
+	m.updateMetrics()
+
+	// Start metrics updater in a goroutine.
+	go func() {
+		ticker := time.NewTicker(m.updateInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			m.updateMetrics()
+		}
+	}()
+}
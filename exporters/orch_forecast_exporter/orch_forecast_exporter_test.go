@@ -0,0 +1,82 @@
+package orch_forecast_exporter
+
+import (
+	"livepeer-exporter/exporters/orch_rewards_exporter"
+	"livepeer-exporter/exporters/orch_tickets_exporter"
+	"reflect"
+	"testing"
+	"time"
+	_ "time/tzdata" // Provides the time zones without a system time zone database.
+)
+
+func TestGetDailyAmounts(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		now         time.Time
+		tickets     []orch_tickets_exporter.WinningTicket
+		rewards     []orch_rewards_exporter.Reward
+		wantFees    []float64
+		wantRewards []float64
+	}{
+		{
+			name:        "no earnings",
+			now:         now,
+			wantFees:    []float64{0, 0, 0},
+			wantRewards: []float64{0, 0, 0},
+		},
+		{
+			name: "bucketed per UTC day",
+			now:  now,
+			tickets: []orch_tickets_exporter.WinningTicket{
+				{Timestamp: time.Date(2024, 3, 7, 0, 0, 0, 0, time.UTC), FaceValue: 1},
+				{Timestamp: time.Date(2024, 3, 7, 23, 59, 59, 0, time.UTC), FaceValue: 2},
+				{Timestamp: time.Date(2024, 3, 9, 12, 0, 0, 0, time.UTC), FaceValue: 4},
+			},
+			rewards: []orch_rewards_exporter.Reward{
+				{Timestamp: time.Date(2024, 3, 8, 6, 0, 0, 0, time.UTC), RewardTokens: 10},
+			},
+			wantFees:    []float64{3, 0, 4},
+			wantRewards: []float64{0, 10, 0},
+		},
+		{
+			name: "outside the window",
+			now:  now,
+			tickets: []orch_tickets_exporter.WinningTicket{
+				{Timestamp: time.Date(2024, 3, 6, 23, 59, 59, 0, time.UTC), FaceValue: 1},
+				{Timestamp: time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), FaceValue: 2},
+				{Timestamp: time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), FaceValue: 4},
+			},
+			wantFees:    []float64{0, 0, 0},
+			wantRewards: []float64{0, 0, 0},
+		},
+		{
+			name: "daylight saving time change",
+			now:  now.In(newYork),
+			tickets: []orch_tickets_exporter.WinningTicket{
+				{Timestamp: time.Date(2024, 3, 9, 23, 30, 0, 0, time.UTC).In(newYork), FaceValue: 1},
+			},
+			rewards: []orch_rewards_exporter.Reward{
+				{Timestamp: time.Date(2024, 3, 7, 0, 30, 0, 0, time.UTC).In(newYork), RewardTokens: 1},
+			},
+			wantFees:    []float64{0, 0, 1},
+			wantRewards: []float64{1, 0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fees, rewards := getDailyAmounts(tt.now, 3, tt.tickets, tt.rewards)
+			if !reflect.DeepEqual(fees, tt.wantFees) {
+				t.Errorf("getDailyAmounts() fees = %v, want %v", fees, tt.wantFees)
+			}
+			if !reflect.DeepEqual(rewards, tt.wantRewards) {
+				t.Errorf("getDailyAmounts() rewards = %v, want %v", rewards, tt.wantRewards)
+			}
+		})
+	}
+}
@@ -0,0 +1,288 @@
+// Package orch_gas_exporter implements a Livepeer orchestrator gas exporter that fetches the current Arbitrum
+// base and priority fees and the receipts of the reward and ticket redemption transactions of the
+// orch_rewards_exporter and orch_tickets_exporter from an Arbitrum JSON-RPC endpoint and exposes the L1 and L2
+// gas cost breakdown of these transactions via Prometheus metrics.
+package orch_gas_exporter
+
+import (
+	"fmt"
+	"livepeer-exporter/exporters/orch_rewards_exporter"
+	"livepeer-exporter/exporters/orch_tickets_exporter"
+	"livepeer-exporter/fetcher"
+	"livepeer-exporter/receipts"
+	"livepeer-exporter/util"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// The transaction types of which the gas costs are broken down.
+const (
+	rewardTransactionType     = "reward"
+	redemptionTransactionType = "redemption"
+)
+
+// block represents the structure of the eth_getBlockByNumber result.
+type block struct {
+	BaseFeePerGas string
+}
+
+// transaction represents a reward or ticket redemption transaction of the orchestrator.
+type transaction struct {
+	ID        string    // The transaction hash.
+	Type      string    // The transaction type.
+	Timestamp time.Time // The block time of the transaction.
+}
+
+// gasData represents the data fetched from the JSON-RPC endpoint.
+type gasData struct {
+	sync.Mutex
+
+	BaseFee     float64 // The base fee of the latest block in Wei.
+	PriorityFee float64 // The suggested priority fee in Wei.
+}
+
+// OrchGasExporter fetches the Arbitrum gas fees and transaction receipts and exposes them via Prometheus.
+type OrchGasExporter struct {
+	// Metrics.
+	BaseFee           prometheus.Gauge
+	PriorityFee       prometheus.Gauge
+	TransactionL1Cost *prometheus.GaugeVec
+	TransactionL2Cost *prometheus.GaugeVec
+	L1GasCost         *prometheus.GaugeVec
+	L2GasCost         *prometheus.GaugeVec
+	L1GasCostShare    *prometheus.GaugeVec
+
+	// Config settings.
+	fetchInterval  time.Duration // How often to fetch data.
+	updateInterval time.Duration // How often to update metrics.
+	rpcURL         string        // The JSON-RPC endpoint to fetch data from.
+
+	// Data.
+	gasData *gasData // The data returned by the JSON-RPC endpoint.
+
+	// Data sources.
+	receiptProvider     *receipts.ReceiptProvider                  // Provides the transaction receipts.
+	orchTicketsExporter *orch_tickets_exporter.OrchTicketsExporter // Provides the ticket redemption transactions.
+	orchRewardsExporter *orch_rewards_exporter.OrchRewardsExporter // Provides the reward transactions.
+}
+
+// initMetrics initializes the gas metrics.
+func (m *OrchGasExporter) initMetrics() {
+	m.BaseFee = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_arbitrum_base_fee",
+			Help: "The base fee of the latest Arbitrum block in Gwei.",
+		},
+	)
+	m.PriorityFee = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_arbitrum_priority_fee",
+			Help: "The suggested Arbitrum priority fee in Gwei.",
+		},
+	)
+	m.TransactionL1Cost = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_transaction_l1_gas_cost",
+			Help: "The L1 gas cost for each reward and ticket redemption transaction in Gwei.",
+		},
+		[]string{"id", "type"},
+	)
+	m.TransactionL2Cost = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_transaction_l2_gas_cost",
+			Help: "The L2 gas cost for each reward and ticket redemption transaction in Gwei.",
+		},
+		[]string{"id", "type"},
+	)
+	m.L1GasCost = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_l1_gas_cost_eth",
+			Help: "The L1 gas cost of the reward and ticket redemption transactions per period in ETH.",
+		},
+		[]string{"type", "period"},
+	)
+	m.L2GasCost = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_l2_gas_cost_eth",
+			Help: "The L2 gas cost of the reward and ticket redemption transactions per period in ETH.",
+		},
+		[]string{"type", "period"},
+	)
+	m.L1GasCostShare = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_l1_gas_cost_share",
+			Help: "The share of the L1 gas cost in the total gas cost of the reward and ticket redemption transactions per period.",
+		},
+		[]string{"type", "period"},
+	)
+}
+
+// registerMetrics registers the gas metrics with Prometheus.
+func (m *OrchGasExporter) registerMetrics() {
+	prometheus.MustRegister(
+		m.BaseFee,
+		m.PriorityFee,
+		m.TransactionL1Cost,
+		m.TransactionL2Cost,
+		m.L1GasCost,
+		m.L2GasCost,
+		m.L1GasCostShare,
+	)
+}
+
+// getTransactions returns the reward and ticket redemption transactions of the orchestrator.
+func (m *OrchGasExporter) getTransactions() []transaction {
+	var transactions []transaction
+	for _, reward := range m.orchRewardsExporter.Rewards() {
+		transactions = append(transactions, transaction{ID: reward.ID, Type: rewardTransactionType, Timestamp: reward.Timestamp})
+	}
+	for _, ticket := range m.orchTicketsExporter.Tickets() {
+		transactions = append(transactions, transaction{ID: ticket.ID, Type: redemptionTransactionType, Timestamp: ticket.Timestamp})
+	}
+	return transactions
+}
+
+// fetchFees fetches the base fee of the latest block and the suggested priority fee.
+func (m *OrchGasExporter) fetchFees() error {
+	var latestBlock block
+	blockFetcher := fetcher.Fetcher{
+		URL:  m.rpcURL,
+		Data: &latestBlock,
+	}
+	if err := blockFetcher.FetchJSONRPCData("eth_getBlockByNumber", "latest", false); err != nil {
+		return err
+	}
+	baseFee, ok := util.ParseQuantity(latestBlock.BaseFeePerGas)
+	if !ok {
+		return fmt.Errorf("invalid base fee '%s'", latestBlock.BaseFeePerGas)
+	}
+
+	var hexPriorityFee string
+	priorityFeeFetcher := fetcher.Fetcher{
+		URL:  m.rpcURL,
+		Data: &hexPriorityFee,
+	}
+	if err := priorityFeeFetcher.FetchJSONRPCData("eth_maxPriorityFeePerGas"); err != nil {
+		return err
+	}
+	priorityFee, ok := util.ParseQuantity(hexPriorityFee)
+	if !ok {
+		return fmt.Errorf("invalid priority fee '%s'", hexPriorityFee)
+	}
+
+	m.gasData.Mutex.Lock()
+	m.gasData.BaseFee = baseFee
+	m.gasData.PriorityFee = priorityFee
+	m.gasData.Mutex.Unlock()
+	return nil
+}
+
+// fetchData fetches the gas fees and the receipts of the transactions that were not fetched before.
+func (m *OrchGasExporter) fetchData() {
+	if err := m.fetchFees(); err != nil {
+		log.Printf("Error fetching gas fees: %v", err)
+	}
+
+	var ids []string
+	for _, tx := range m.getTransactions() {
+		ids = append(ids, tx.ID)
+	}
+	m.receiptProvider.Fetch(ids)
+}
+
+// updateMetrics updates the metrics with the data fetched from the JSON-RPC endpoint.
+func (m *OrchGasExporter) updateMetrics() {
+	transactions := m.getTransactions()
+
+	m.gasData.Mutex.Lock()
+	defer m.gasData.Mutex.Unlock()
+
+	m.BaseFee.Set(m.gasData.BaseFee / 1e9)         // Expressed in Gwei.
+	m.PriorityFee.Set(m.gasData.PriorityFee / 1e9) // Expressed in Gwei.
+
+	// Set the metrics for each transaction.
+	for _, tx := range transactions {
+		receipt, ok := m.receiptProvider.GetReceipt(tx.ID)
+		if !ok {
+			continue
+		}
+		m.TransactionL1Cost.WithLabelValues(tx.ID, tx.Type).Set(receipt.L1Cost * 1e9) // Expressed in Gwei.
+		m.TransactionL2Cost.WithLabelValues(tx.ID, tx.Type).Set(receipt.L2Cost * 1e9) // Expressed in Gwei.
+	}
+
+	// Calculate the L1 and L2 gas costs per transaction type and period.
+	for _, txType := range []string{rewardTransactionType, redemptionTransactionType} {
+		for _, period := range util.GetPeriods(time.Now()) {
+			var l1Cost, l2Cost float64
+			for _, tx := range transactions {
+				receipt, ok := m.receiptProvider.GetReceipt(tx.ID)
+				if !ok || tx.Type != txType || tx.Timestamp.Before(period.Start) {
+					continue
+				}
+				l1Cost += receipt.L1Cost
+				l2Cost += receipt.L2Cost
+			}
+			m.L1GasCost.WithLabelValues(txType, period.Name).Set(l1Cost)
+			m.L2GasCost.WithLabelValues(txType, period.Name).Set(l2Cost)
+			if l1Cost+l2Cost > 0 {
+				m.L1GasCostShare.WithLabelValues(txType, period.Name).Set(l1Cost / (l1Cost + l2Cost))
+			}
+		}
+	}
+}
+
+// NewOrchGasExporter creates a new OrchGasExporter.
+func NewOrchGasExporter(rpcURL string, fetchInterval time.Duration, updateInterval time.Duration, receiptProvider *receipts.ReceiptProvider, orchTicketsExporter *orch_tickets_exporter.OrchTicketsExporter, orchRewardsExporter *orch_rewards_exporter.OrchRewardsExporter) *OrchGasExporter {
+	exporter := &OrchGasExporter{
+		fetchInterval:       fetchInterval,
+		updateInterval:      updateInterval,
+		rpcURL:              rpcURL,
+		gasData:             &gasData{},
+		receiptProvider:     receiptProvider,
+		orchTicketsExporter: orchTicketsExporter,
+		orchRewardsExporter: orchRewardsExporter,
+	}
+
+	// Initialize metrics.
+	exporter.initMetrics()
+	exporter.registerMetrics()
+
+	return exporter
+}
+
+// GasPrice returns the current Arbitrum gas price, i.e. the base fee plus the priority fee, in Wei.
+func (m *OrchGasExporter) GasPrice() float64 {
+	m.gasData.Mutex.Lock()
+	defer m.gasData.Mutex.Unlock()
+	return m.gasData.BaseFee + m.gasData.PriorityFee
+}
+
+// Start starts the OrchGasExporter.
+func (m *OrchGasExporter) Start() {
+	// Fetch initial data and update metrics.
+	m.fetchData()
+	m.updateMetrics()
+
+	// Start fetcher in a goroutine.
+	go func() {
+		ticker := time.NewTicker(m.fetchInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			m.fetchData()
+		}
+	}()
+
+	// Start metrics updater in a goroutine.
+	go func() {
+		ticker := time.NewTicker(m.updateInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			m.updateMetrics()
+		}
+	}()
+}
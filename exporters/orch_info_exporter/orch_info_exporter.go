@@ -1,13 +1,19 @@
 // Package orch_info_exporter implements a Livepeer orchestrator info exporter that fetches data
 // from the Livepeer subgraph GraphQL API endpoint and exposes info about the orchestrator via Prometheus metrics.
+// It also estimates the delegator yield from the orchestrator's reward and fee history using the prices
+// of the crypto_prices_exporter.
 package orch_info_exporter
 
 import (
+	"encoding/json"
 	"fmt"
 	"livepeer-exporter/constants"
+	"livepeer-exporter/exporters/crypto_prices_exporter"
 	"livepeer-exporter/fetcher"
 	"livepeer-exporter/util"
 	"log"
+	"math"
+	"net/http"
 	"strconv"
 	"sync"
 	"time"
@@ -16,15 +22,23 @@ import (
 )
 
 var (
-	orchInfoEndpoint = constants.LivePeerSubgraphEndpoint
+	orchInfoEndpoint     = constants.LivePeerSubgraphEndpoint
+	currentRoundEndpoint = "https://explorer.livepeer.org/api/current-round"
 
 	// Global variables to track whether a warning has already been logged for a invalid delegator address.
 	hasLoggedNoDelegator bool
 )
 
-// graphqlQuery represents the GraphQL query to fetch data from the GraphQL API.
-const graphqlQueryTemplate = `
-{
+// PoolsLimit is the maximum number of pools fetched from the GraphQL API. Lookback windows longer than this
+// number of rounds are not covered by the fetched pools.
+const PoolsLimit = 1000
+
+// l1BlockTime is the average time between L1 blocks, used to calculate the number of rounds per year.
+const l1BlockTime = 12 * time.Second
+
+// transcoderQueryTemplate represents the part of the GraphQL query that fetches the orchestrator and the
+// protocol data from the GraphQL API.
+const transcoderQueryTemplate = `
 	transcoder(id: "%s") {
 		delegator {
 			bondedAmount
@@ -41,8 +55,12 @@ const graphqlQueryTemplate = `
 		activationRound
 		active
 		feeShare
-		pools {
+		pools(orderBy: round__startBlock, orderDirection: desc, first: %d) {
 			rewardTokens
+			fees
+			totalStake
+			rewardCut
+			feeShare
 			round {
 				id
 			}
@@ -59,23 +77,50 @@ const graphqlQueryTemplate = `
 		}
 	}
 	protocol(id: "0") {
+		roundLength
 		currentRound {
 			id
+			startBlock
 		}
 	}
-}
 `
 
+// activeSetQuery represents the part of the GraphQL query that fetches the active orchestrators and the
+// largest inactive orchestrator from the GraphQL API.
+const activeSetQuery = `
+	transcoders(where: {active: true}, orderBy: totalStake, orderDirection: desc, first: 1000) {
+		id
+		totalStake
+	}
+	inactiveTranscoders: transcoders(where: {active: false}, orderBy: totalStake, orderDirection: desc, first: 1) {
+		id
+		totalStake
+	}
+`
+
+// graphqlQuery represents the GraphQL query to fetch data from the GraphQL API.
+const graphqlQueryTemplate = "{" + transcoderQueryTemplate + activeSetQuery + "}"
+
 // delegatingInfoResponse represents the structure of the pools field contained in the GraphQL API response.
 type pool struct {
 	RewardTokens string
+	Fees         string
+	TotalStake   string
+	RewardCut    string
+	FeeShare     string
 	Round        struct {
 		ID string
 	}
 }
 
-// transcoderResponse represents the structure of the GraphQL API response.
-type transcoderResponse struct {
+// activeTranscoder represents the structure of the transcoders field contained in the GraphQL API response.
+type activeTranscoder struct {
+	ID         string
+	TotalStake string
+}
+
+// TranscoderResponse represents the structure of the GraphQL API response.
+type TranscoderResponse struct {
 	sync.Mutex
 
 	// Response data.
@@ -105,16 +150,31 @@ type transcoderResponse struct {
 				BondedAmount string
 			}
 		}
-		Protocol struct {
+		Transcoders         []activeTranscoder
+		InactiveTranscoders []activeTranscoder
+		Protocol            struct {
+			RoundLength  string
 			CurrentRound struct {
-				ID string
+				ID         string
+				StartBlock string
 			}
 		}
 	}
 }
 
-// orchInfo represents the parsed data from the the Livepeer subgraph GraphQL API.
-type orchInfo struct {
+// currentRoundResponse represents the structure of the data returned by the Livepeer current round API.
+type currentRoundResponse struct {
+	sync.Mutex
+
+	// Response data.
+	ID             float64 `json:"id"`
+	StartBlock     float64 `json:"startBlock"`
+	Initialized    bool    `json:"initialized"`
+	CurrentL1Block float64 `json:"currentL1Block"`
+}
+
+// OrchInfo represents the parsed data from the the Livepeer subgraph GraphQL API.
+type OrchInfo struct {
 	BondedAmount       float64
 	TotalStake         float64
 	LastClaimRound     float64
@@ -131,29 +191,99 @@ type orchInfo struct {
 	TotalVolumeETH     float64
 	OrchStake          float64
 	RewardCallRatio    float64
+
+	// Round progress.
+	RoundLength                   float64
+	RoundStartBlock               float64
+	CurrentL1Block                float64
+	RoundBlocksRemaining          float64
+	RoundProgressRatio            float64
+	RewardCalledThisRound         float64
+	ConsecutiveMissedRewardRounds float64
+
+	// Active set position.
+	StakeRank       float64
+	ActiveSetCutoff float64
+	ActiveSetMargin float64
 }
 
-// getRewardCallRatio calculates the ratio of rounds in the last 30 days that the orchestrator claimed rewards.
-func getRewardCallRatio(pools []pool, currentRound, activationRound int) float64 {
-	// Calculate the round 30 days back
-	thirtyDaysBack := currentRound - 30
+// RoundsPerYear returns the number of rounds per year based on the round length.
+func (info OrchInfo) RoundsPerYear() float64 {
+	return getRoundsPerYear(info.RoundLength)
+}
+
+// getActiveSetPosition returns the stake rank of the orchestrator among the active orchestrators, the
+// stake of the last active set member and the amount of LPT the orchestrator can lose before falling
+// out of the active set. An active orchestrator falls out when the best inactive orchestrator overtakes
+// it, so the margin is measured against the stake of that challenger. When the orchestrator is not
+// active, its rank is 0 and the margin is negative and represents the LPT that is needed to enter the
+// active set.
+func getActiveSetPosition(transcoders []activeTranscoder, challengers []activeTranscoder, orchAddress string, orchStake float64) (rank int, cutoff float64, margin float64) {
+	if len(transcoders) == 0 {
+		return 0, 0, 0
+	}
 
-	// If the activation round is less than 30 days back, use it instead
-	if activationRound > thirtyDaysBack {
-		thirtyDaysBack = activationRound
+	for i, transcoder := range transcoders {
+		if transcoder.ID == orchAddress {
+			rank = i + 1
+		}
+	}
+	cutoff, _ = strconv.ParseFloat(transcoders[len(transcoders)-1].TotalStake, 64)
+	if rank == 0 {
+		return rank, cutoff, orchStake - cutoff
 	}
 
-	// Create a map of all round IDs in the pools
-	poolRounds := make(map[int]bool)
+	var challengerStake float64
+	if len(challengers) > 0 {
+		challengerStake, _ = strconv.ParseFloat(challengers[0].TotalStake, 64)
+	}
+	return rank, cutoff, orchStake - challengerStake
+}
+
+// getRewardedRounds returns the rounds in which the orchestrator claimed rewards. A pool exists for every
+// round in which the orchestrator was active, so only pools with minted reward tokens count as rewarded.
+func getRewardedRounds(pools []pool) map[int]bool {
+	rewardedRounds := make(map[int]bool)
 	for _, pool := range pools {
+		rewardTokens, err := strconv.ParseFloat(pool.RewardTokens, 64)
+		if err != nil || rewardTokens <= 0 {
+			continue
+		}
 		roundID, _ := strconv.Atoi(pool.Round.ID)
-		poolRounds[roundID] = true
+		rewardedRounds[roundID] = true
 	}
+	return rewardedRounds
+}
 
-	// Count the rounds from the current round to 30 days back that exist in the pools
+// getConsecutiveMissedRewardRounds returns the number of consecutive rounds before the current round in
+// which the orchestrator did not claim rewards. The current round is not counted since rewards can still
+// be claimed.
+func getConsecutiveMissedRewardRounds(pools []pool, currentRound, activationRound int) int {
+	rewardedRounds := getRewardedRounds(pools)
+	missedRounds := 0
+	for round := currentRound - 1; round >= activationRound && !rewardedRounds[round]; round-- {
+		missedRounds++
+	}
+	return missedRounds
+}
+
+// getRewardCallRatio calculates the ratio of rounds in the given number of rounds back that the orchestrator claimed rewards.
+func getRewardCallRatio(pools []pool, currentRound, activationRound, window int) float64 {
+	// Calculate the round the window starts
+	windowStart := currentRound - window
+
+	// If the activation round is after the start of the window, use it instead
+	if activationRound > windowStart {
+		windowStart = activationRound
+	}
+
+	// Create a map of all rounds in which rewards were claimed
+	poolRounds := getRewardedRounds(pools)
+
+	// Count the rounds from the current round to the start of the window that exist in the pools
 	rewardedRounds := 0
-	totalRounds := currentRound - thirtyDaysBack + 1
-	for round := currentRound; round >= thirtyDaysBack; round-- {
+	totalRounds := currentRound - windowStart + 1
+	for round := currentRound; round >= windowStart; round-- {
 		if poolRounds[round] {
 			rewardedRounds++
 		}
@@ -163,6 +293,76 @@ func getRewardCallRatio(pools []pool, currentRound, activationRound int) float64
 	return float64(rewardedRounds) / float64(totalRounds)
 }
 
+// getMissedRewardRounds returns the rounds since the activation round in which the orchestrator did not
+// claim rewards, together with the first round that was checked. The current round is not included since
+// rewards can still be claimed. When the pool limit is reached, only the rounds covered by the fetched
+// pools are included.
+func getMissedRewardRounds(pools []pool, currentRound, activationRound int) (firstRound int, missedRounds []int) {
+	firstRound = activationRound
+	if len(pools) >= PoolsLimit {
+		oldestRound := currentRound
+		for _, pool := range pools {
+			if round, _ := strconv.Atoi(pool.Round.ID); round < oldestRound {
+				oldestRound = round
+			}
+		}
+		if oldestRound > firstRound {
+			firstRound = oldestRound
+		}
+	}
+
+	rewardedRounds := getRewardedRounds(pools)
+	missedRounds = []int{}
+	for round := firstRound; round < currentRound; round++ {
+		if !rewardedRounds[round] {
+			missedRounds = append(missedRounds, round)
+		}
+	}
+	return firstRound, missedRounds
+}
+
+// getPoolEarnings returns the LPT rewards and ETH fees earned by the orchestrator's pool in the given
+// number of completed rounds before the current round, together with the number of these rounds in
+// which the orchestrator was activated.
+func getPoolEarnings(pools []pool, currentRound, activationRound, window int) (rewards float64, fees float64, rounds int) {
+	firstRound := currentRound - window
+	if activationRound > firstRound {
+		firstRound = activationRound
+	}
+	for _, pool := range pools {
+		round, _ := strconv.Atoi(pool.Round.ID)
+		if round < firstRound || round >= currentRound {
+			continue
+		}
+		var poolRewards, poolFees float64
+		util.SetFloatFromStr(&poolRewards, pool.RewardTokens)
+		util.SetFloatFromStr(&poolFees, pool.Fees)
+		rewards += poolRewards
+		fees += poolFees
+	}
+	return rewards, fees, currentRound - firstRound
+}
+
+// getPoolHistory returns the pools of the given number of rounds up to and including the current round.
+func getPoolHistory(pools []pool, currentRound, rounds int) []pool {
+	var history []pool
+	for _, pool := range pools {
+		round, _ := strconv.Atoi(pool.Round.ID)
+		if round > currentRound-rounds && round <= currentRound {
+			history = append(history, pool)
+		}
+	}
+	return history
+}
+
+// getRoundsPerYear returns the number of rounds per year for the given round length in L1 blocks.
+func getRoundsPerYear(roundLength float64) float64 {
+	if roundLength <= 0 {
+		return 0
+	}
+	return (365 * 24 * time.Hour).Seconds() / (roundLength * l1BlockTime.Seconds())
+}
+
 // OrchInfoExporter fetches data from the API and exposes orchestrator info via Prometheus.
 type OrchInfoExporter struct {
 	// Metrics.
@@ -183,19 +383,55 @@ type OrchInfoExporter struct {
 	OrchStake          prometheus.Gauge
 	RewardCallRatio    prometheus.Gauge
 
+	// Round progress metrics.
+	RoundBlocksRemaining          prometheus.Gauge
+	RoundProgressRatio            prometheus.Gauge
+	RewardCalledThisRound         prometheus.Gauge
+	ConsecutiveMissedRewardRounds prometheus.Gauge
+
+	// Active set position metrics.
+	StakeRank       prometheus.Gauge
+	ActiveSetCutoff prometheus.Gauge
+	ActiveSetMargin prometheus.Gauge
+
+	// Delegator yield metrics.
+	DelegatorAPR *prometheus.GaugeVec
+	DelegatorAPY *prometheus.GaugeVec
+
+	// Reward call metrics.
+	RewardCallRatios *prometheus.GaugeVec
+	RewardCalled     *prometheus.GaugeVec
+
+	// Pool history metrics.
+	PoolRewardTokens *prometheus.GaugeVec
+	PoolFees         *prometheus.GaugeVec
+	PoolTotalStake   *prometheus.GaugeVec
+	PoolRewardCut    *prometheus.GaugeVec
+	PoolFeeCut       *prometheus.GaugeVec
+
 	// Config settings.
+	orchAddress          string        // The orchestrator address.
 	fetchInterval        time.Duration // How often to fetch data.
 	updateInterval       time.Duration // How often to update metrics.
 	orchAddressSecondary string        // The secondary orchestrator address.
 	orchInfoEndpoint     string        // The endpoint to fetch data from.
 	orchInfoGraphqlQuery string        // The GraphQL query to fetch data from the GraphQL API.
+	currentRoundEndpoint string        // The endpoint to fetch the current round data from.
+	aprLookbackRounds    []int         // The number of rounds over which the delegator yield is estimated.
+	poolHistoryRounds    int           // The number of rounds of pool history to expose.
+	rewardCallWindows    []int         // The number of rounds over which the reward call ratio is calculated.
 
 	// Data.
-	transcoderResponse *transcoderResponse // The data returned by the API.
-	orchInfo           *orchInfo           // The data returned by the orchestrator API, parsed into a struct.
+	transcoderResponse   *TranscoderResponse   // The data returned by the API.
+	currentRoundResponse *currentRoundResponse // The data returned by the current round API.
+	orchInfo             *OrchInfo             // The data returned by the orchestrator API, parsed into a struct.
 
 	// Fetchers.
-	orchInfoFetcher fetcher.Fetcher
+	orchInfoFetcher     fetcher.Fetcher
+	currentRoundFetcher fetcher.Fetcher
+
+	// Data sources.
+	cryptoPricesExporter *crypto_prices_exporter.CryptoPricesExporter // Provides the LPT and ETH prices.
 }
 
 // initMetrics initializes the orchestrator info metrics.
@@ -296,6 +532,111 @@ func (m *OrchInfoExporter) initMetrics() {
 			Help: "How often an orchestrator claimed rewards in the last thirty rounds.",
 		},
 	)
+	m.RoundBlocksRemaining = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_round_blocks_remaining",
+			Help: "The number of L1 blocks remaining in the current round.",
+		},
+	)
+	m.RoundProgressRatio = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_round_progress_ratio",
+			Help: "The proportion of the current round that has passed.",
+		},
+	)
+	m.RewardCalledThisRound = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_reward_called_this_round",
+			Help: "Whether the orchestrator claimed rewards in the current round.",
+		},
+	)
+	m.ConsecutiveMissedRewardRounds = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_consecutive_missed_reward_rounds",
+			Help: "The number of consecutive rounds before the current round in which the orchestrator did not claim rewards.",
+		},
+	)
+	m.StakeRank = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_stake_rank",
+			Help: "The stake rank of the orchestrator among the active orchestrators (0 if not active).",
+		},
+	)
+	m.ActiveSetCutoff = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_active_set_cutoff_stake",
+			Help: "The total stake of the last member of the active set.",
+		},
+	)
+	m.ActiveSetMargin = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_active_set_margin",
+			Help: "The LPT the orchestrator can lose before leaving the active set, or if negative, the LPT it needs to enter it.",
+		},
+	)
+	m.DelegatorAPR = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_delegator_apr",
+			Help: "The estimated annual percentage rate of the orchestrator's delegators based on the earnings in the lookback window.",
+		},
+		[]string{"window", "denomination"},
+	)
+	m.DelegatorAPY = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_delegator_apy",
+			Help: "The estimated annual percentage yield of the orchestrator's delegators when compounding each round.",
+		},
+		[]string{"window", "denomination"},
+	)
+	m.RewardCallRatios = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_reward_claim_ratio",
+			Help: "How often the orchestrator claimed rewards in the lookback window.",
+		},
+		[]string{"window"},
+	)
+	m.RewardCalled = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_reward_called",
+			Help: "Whether the orchestrator claimed rewards in each round.",
+		},
+		[]string{"round"},
+	)
+	m.PoolRewardTokens = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_pool_reward_tokens",
+			Help: "The LPT rewards earned by the orchestrator's pool in each round.",
+		},
+		[]string{"round"},
+	)
+	m.PoolFees = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_pool_fees",
+			Help: "The ETH fees earned by the orchestrator's pool in each round.",
+		},
+		[]string{"round"},
+	)
+	m.PoolTotalStake = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_pool_total_stake",
+			Help: "The total stake of the orchestrator's pool in each round.",
+		},
+		[]string{"round"},
+	)
+	m.PoolRewardCut = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_pool_reward_cut",
+			Help: "The reward cut that applied to the orchestrator's pool in each round.",
+		},
+		[]string{"round"},
+	)
+	m.PoolFeeCut = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_pool_fee_cut",
+			Help: "The fee cut that applied to the orchestrator's pool in each round.",
+		},
+		[]string{"round"},
+	)
 }
 
 // registerMetrics registers the orchestrator info metrics with Prometheus.
@@ -317,55 +658,195 @@ func (m *OrchInfoExporter) registerMetrics() {
 		m.TotalVolumeETH,
 		m.OrchStake,
 		m.RewardCallRatio,
+		m.RoundBlocksRemaining,
+		m.RoundProgressRatio,
+		m.RewardCalledThisRound,
+		m.ConsecutiveMissedRewardRounds,
+		m.StakeRank,
+		m.ActiveSetCutoff,
+		m.ActiveSetMargin,
+		m.DelegatorAPR,
+		m.DelegatorAPY,
+		m.RewardCallRatios,
+		m.RewardCalled,
+		m.PoolRewardTokens,
+		m.PoolFees,
+		m.PoolTotalStake,
+		m.PoolRewardCut,
+		m.PoolFeeCut,
 	)
 }
 
-// parseMetrics parses the values from the transcoderResponse and delegatingInfoResponse and populates the orchInfo struct.
-func (m *OrchInfoExporter) parseMetrics() {
+// GetGraphqlQuery returns the GraphQL query used to fetch the info of the given orchestrator and its
+// optional secondary address from the Livepeer subgraph GraphQL API.
+func GetGraphqlQuery(orchAddress string, orchAddrSecondary string) string {
+	return fmt.Sprintf(graphqlQueryTemplate, orchAddress, PoolsLimit, orchAddrSecondary)
+}
+
+// GetTranscoderGraphqlQuery returns the GraphQL query used to fetch the info of the given orchestrator
+// without the active set from the Livepeer subgraph GraphQL API.
+func GetTranscoderGraphqlQuery(orchAddress string) string {
+	return fmt.Sprintf("{"+transcoderQueryTemplate+"}", orchAddress, PoolsLimit, "")
+}
+
+// GetActiveSetGraphqlQuery returns the GraphQL query used to fetch the active set from the Livepeer
+// subgraph GraphQL API.
+func GetActiveSetGraphqlQuery() string {
+	return "{" + activeSetQuery + "}"
+}
+
+// ParseOrchInfo parses the values from the transcoderResponse of the given orchestrator and populates
+// the given OrchInfo struct.
+func ParseOrchInfo(response *TranscoderResponse, info *OrchInfo, orchAddress string, orchAddrSecondary string) {
 	// Parse and set the orchestrator info.
-	util.SetFloatFromStr(&m.orchInfo.BondedAmount, m.transcoderResponse.Data.Transcoder.Delegator.BondedAmount)
-	util.SetFloatFromStr(&m.orchInfo.TotalStake, m.transcoderResponse.Data.Transcoder.TotalStake)
-	util.SetFloatFromStr(&m.orchInfo.LastClaimRound, m.transcoderResponse.Data.Transcoder.Delegator.LastClaimRound.ID)
-	util.SetFloatFromStr(&m.orchInfo.StartRound, m.transcoderResponse.Data.Transcoder.Delegator.StartRound)
-	util.SetFloatFromStr(&m.orchInfo.WithdrawnFees, m.transcoderResponse.Data.Transcoder.Delegator.WithdrawnFees)
-	util.SetFloatFromStr(&m.orchInfo.CurrentRound, m.transcoderResponse.Data.Protocol.CurrentRound.ID)
-	util.SetFloatFromStr(&m.orchInfo.ActivationRound, m.transcoderResponse.Data.Transcoder.ActivationRound)
-	m.orchInfo.Active = util.BoolToFloat64(m.transcoderResponse.Data.Transcoder.Active)
-	util.SetFloatFromStr(&m.orchInfo.LastRewardRound, m.transcoderResponse.Data.Transcoder.LastRewardRound.ID)
-	util.SetFloatFromStr(&m.orchInfo.NinetyDayVolumeETH, m.transcoderResponse.Data.Transcoder.NinetyDayVolumeETH)
-	util.SetFloatFromStr(&m.orchInfo.ThirtyDayVolumeETH, m.transcoderResponse.Data.Transcoder.ThirtyDayVolumeETH)
-	util.SetFloatFromStr(&m.orchInfo.TotalVolumeETH, m.transcoderResponse.Data.Transcoder.TotalVolumeETH)
-	m.orchInfo.RewardCallRatio = getRewardCallRatio(m.transcoderResponse.Data.Transcoder.Pools, int(m.orchInfo.CurrentRound), int(m.orchInfo.ActivationRound))
+	util.SetFloatFromStr(&info.BondedAmount, response.Data.Transcoder.Delegator.BondedAmount)
+	util.SetFloatFromStr(&info.TotalStake, response.Data.Transcoder.TotalStake)
+	util.SetFloatFromStr(&info.LastClaimRound, response.Data.Transcoder.Delegator.LastClaimRound.ID)
+	util.SetFloatFromStr(&info.StartRound, response.Data.Transcoder.Delegator.StartRound)
+	util.SetFloatFromStr(&info.WithdrawnFees, response.Data.Transcoder.Delegator.WithdrawnFees)
+	util.SetFloatFromStr(&info.CurrentRound, response.Data.Protocol.CurrentRound.ID)
+	util.SetFloatFromStr(&info.ActivationRound, response.Data.Transcoder.ActivationRound)
+	info.Active = util.BoolToFloat64(response.Data.Transcoder.Active)
+	util.SetFloatFromStr(&info.LastRewardRound, response.Data.Transcoder.LastRewardRound.ID)
+	util.SetFloatFromStr(&info.NinetyDayVolumeETH, response.Data.Transcoder.NinetyDayVolumeETH)
+	util.SetFloatFromStr(&info.ThirtyDayVolumeETH, response.Data.Transcoder.ThirtyDayVolumeETH)
+	util.SetFloatFromStr(&info.TotalVolumeETH, response.Data.Transcoder.TotalVolumeETH)
+	info.RewardCallRatio = getRewardCallRatio(response.Data.Transcoder.Pools, int(info.CurrentRound), int(info.ActivationRound), 30)
 
 	// Calculate and set reward and fee cut proportions.
-	feeShare, err := util.StringToFloat64(m.transcoderResponse.Data.Transcoder.FeeShare)
+	feeShare, err := util.StringToFloat64(response.Data.Transcoder.FeeShare)
 	if err != nil {
 		log.Printf("Error parsing fee share: %v", err)
 	} else {
-		m.orchInfo.FeeCut = util.Round(1-feeShare*1e-6, 2)
+		info.FeeCut = util.Round(1-feeShare*1e-6, 2)
 	}
-	rewardCut, err := util.StringToFloat64(m.transcoderResponse.Data.Transcoder.RewardCut)
+	rewardCut, err := util.StringToFloat64(response.Data.Transcoder.RewardCut)
 	if err != nil {
 		log.Printf("Error parsing reward cut: %v", err)
 	} else {
-		m.orchInfo.RewardCut = util.Round(rewardCut*1e-6, 2)
+		info.RewardCut = util.Round(rewardCut*1e-6, 2)
 	}
 
 	// Calculate and set the orchestrator stake.
 	// NOTE: If the orchestrator has a secondary address, we need to add the stake from the secondary address to the stake from the primary address.
-	util.SetFloatFromStr(&m.orchInfo.OrchStake, m.transcoderResponse.Data.Transcoder.Delegator.BondedAmount)
-	if m.orchAddressSecondary != "" {
+	util.SetFloatFromStr(&info.OrchStake, response.Data.Transcoder.Delegator.BondedAmount)
+	if orchAddrSecondary != "" {
 		var secondaryStake float64
-		if len(m.transcoderResponse.Data.Transcoder.Delegators) > 0 {
-			util.SetFloatFromStr(&secondaryStake, m.transcoderResponse.Data.Transcoder.Delegators[0].BondedAmount)
+		if len(response.Data.Transcoder.Delegators) > 0 {
+			util.SetFloatFromStr(&secondaryStake, response.Data.Transcoder.Delegators[0].BondedAmount)
 		} else {
 			secondaryStake = 0
 			if !hasLoggedNoDelegator {
-				log.Printf("No delegator account found for secondary address '%s'", m.orchAddressSecondary)
+				log.Printf("No delegator account found for secondary address '%s'", orchAddrSecondary)
 				hasLoggedNoDelegator = true
 			}
 		}
-		m.orchInfo.OrchStake += secondaryStake
+		info.OrchStake += secondaryStake
+	}
+
+	// Parse the current round info and calculate the reward call status.
+	util.SetFloatFromStr(&info.RoundLength, response.Data.Protocol.RoundLength)
+	util.SetFloatFromStr(&info.RoundStartBlock, response.Data.Protocol.CurrentRound.StartBlock)
+	info.RewardCalledThisRound = util.BoolToFloat64(info.LastRewardRound == info.CurrentRound)
+	info.ConsecutiveMissedRewardRounds = 0
+	if response.Data.Transcoder.Active {
+		info.ConsecutiveMissedRewardRounds = float64(getConsecutiveMissedRewardRounds(response.Data.Transcoder.Pools, int(info.CurrentRound), int(info.ActivationRound)))
+	}
+
+	// Calculate the position of the orchestrator in the active set.
+	rank, cutoff, margin := getActiveSetPosition(response.Data.Transcoders, response.Data.InactiveTranscoders, orchAddress, info.TotalStake)
+	info.StakeRank = float64(rank)
+	info.ActiveSetCutoff = cutoff
+	info.ActiveSetMargin = margin
+}
+
+// parseMetrics parses the values from the transcoderResponse and currentRoundResponse and populates the orchInfo struct.
+func (m *OrchInfoExporter) parseMetrics() {
+	ParseOrchInfo(m.transcoderResponse, m.orchInfo, m.orchAddress, m.orchAddressSecondary)
+
+	// Calculate the round progress.
+	m.currentRoundResponse.Mutex.Lock()
+	m.orchInfo.CurrentL1Block = m.currentRoundResponse.CurrentL1Block
+	m.currentRoundResponse.Mutex.Unlock()
+	if m.orchInfo.RoundLength > 0 && m.orchInfo.CurrentL1Block > 0 {
+		blocksPassed := math.Max(m.orchInfo.CurrentL1Block-m.orchInfo.RoundStartBlock, 0)
+		m.orchInfo.RoundBlocksRemaining = math.Max(m.orchInfo.RoundLength-blocksPassed, 0)
+		m.orchInfo.RoundProgressRatio = math.Min(blocksPassed/m.orchInfo.RoundLength, 1)
+	}
+}
+
+// updateYieldMetrics estimates the delegator yield for each lookback window from the orchestrator's
+// pool earnings. The 'lpt' denomination only includes the LPT rewards, while the 'fiat' denomination
+// also includes the ETH fees valued at the current ETH/LPT price ratio. No yield is exposed while the
+// orchestrator has no stake, since the yield per staked LPT is undefined.
+func (m *OrchInfoExporter) updateYieldMetrics() {
+	m.DelegatorAPR.Reset()
+	m.DelegatorAPY.Reset()
+	roundsPerYear := getRoundsPerYear(m.orchInfo.RoundLength)
+	if roundsPerYear <= 0 || m.orchInfo.TotalStake <= 0 {
+		return
+	}
+	prices := m.cryptoPricesExporter.CryptoPrices()
+
+	for _, window := range m.aprLookbackRounds {
+		rewards, fees, rounds := getPoolEarnings(m.transcoderResponse.Data.Transcoder.Pools, int(m.orchInfo.CurrentRound), int(m.orchInfo.ActivationRound), window)
+		if rounds <= 0 {
+			continue
+		}
+
+		// Calculate the yield per staked LPT of the delegators' share of the earnings.
+		rewardAPR := rewards * (1 - m.orchInfo.RewardCut) / m.orchInfo.TotalStake * roundsPerYear / float64(rounds)
+		feeAPR := fees * (1 - m.orchInfo.FeeCut) / m.orchInfo.TotalStake * roundsPerYear / float64(rounds)
+		yields := map[string]float64{"lpt": rewardAPR}
+		if prices.LPTUSDPrice > 0 {
+			yields["fiat"] = rewardAPR + feeAPR*prices.ETHUSDPrice/prices.LPTUSDPrice
+		}
+
+		windowLabel := strconv.Itoa(window)
+		for denomination, apr := range yields {
+			m.DelegatorAPR.WithLabelValues(windowLabel, denomination).Set(apr)
+			m.DelegatorAPY.WithLabelValues(windowLabel, denomination).Set(math.Pow(1+apr/roundsPerYear, roundsPerYear) - 1)
+		}
+	}
+}
+
+// updateRewardCallMetrics calculates the reward call ratio for each lookback window and whether the
+// orchestrator claimed rewards in each round of the pool history.
+func (m *OrchInfoExporter) updateRewardCallMetrics() {
+	pools := m.transcoderResponse.Data.Transcoder.Pools
+	currentRound, activationRound := int(m.orchInfo.CurrentRound), int(m.orchInfo.ActivationRound)
+	for _, window := range m.rewardCallWindows {
+		m.RewardCallRatios.WithLabelValues(strconv.Itoa(window)).Set(getRewardCallRatio(pools, currentRound, activationRound, window))
+	}
+
+	m.RewardCalled.Reset()
+	rewardedRounds := getRewardedRounds(pools)
+	for round := currentRound; round > currentRound-m.poolHistoryRounds && round >= activationRound; round-- {
+		m.RewardCalled.WithLabelValues(strconv.Itoa(round)).Set(util.BoolToFloat64(rewardedRounds[round]))
+	}
+}
+
+// updatePoolHistoryMetrics exposes the rewards, fees, total stake and cuts of the orchestrator's pool
+// in each round of the pool history.
+func (m *OrchInfoExporter) updatePoolHistoryMetrics() {
+	m.PoolRewardTokens.Reset()
+	m.PoolFees.Reset()
+	m.PoolTotalStake.Reset()
+	m.PoolRewardCut.Reset()
+	m.PoolFeeCut.Reset()
+	for _, pool := range getPoolHistory(m.transcoderResponse.Data.Transcoder.Pools, int(m.orchInfo.CurrentRound), m.poolHistoryRounds) {
+		var rewardTokens, fees, totalStake, rewardCut, feeShare float64
+		util.SetFloatFromStr(&rewardTokens, pool.RewardTokens)
+		util.SetFloatFromStr(&fees, pool.Fees)
+		util.SetFloatFromStr(&totalStake, pool.TotalStake)
+		util.SetFloatFromStr(&rewardCut, pool.RewardCut)
+		util.SetFloatFromStr(&feeShare, pool.FeeShare)
+
+		m.PoolRewardTokens.WithLabelValues(pool.Round.ID).Set(rewardTokens)
+		m.PoolFees.WithLabelValues(pool.Round.ID).Set(fees)
+		m.PoolTotalStake.WithLabelValues(pool.Round.ID).Set(totalStake)
+		m.PoolRewardCut.WithLabelValues(pool.Round.ID).Set(util.Round(rewardCut*1e-6, 2))
+		m.PoolFeeCut.WithLabelValues(pool.Round.ID).Set(util.Round(1-feeShare*1e-6, 2))
 	}
 }
 
@@ -374,6 +855,9 @@ func (m *OrchInfoExporter) updateMetrics() {
 	// Parse the metrics from the response data.
 	m.transcoderResponse.Mutex.Lock()
 	m.parseMetrics()
+	m.updateYieldMetrics()
+	m.updateRewardCallMetrics()
+	m.updatePoolHistoryMetrics()
 	m.transcoderResponse.Mutex.Unlock()
 
 	// Set the metrics.
@@ -393,18 +877,32 @@ func (m *OrchInfoExporter) updateMetrics() {
 	m.TotalVolumeETH.Set(m.orchInfo.TotalVolumeETH)
 	m.OrchStake.Set(m.orchInfo.OrchStake)
 	m.RewardCallRatio.Set(m.orchInfo.RewardCallRatio)
+	m.RoundBlocksRemaining.Set(m.orchInfo.RoundBlocksRemaining)
+	m.RoundProgressRatio.Set(m.orchInfo.RoundProgressRatio)
+	m.RewardCalledThisRound.Set(m.orchInfo.RewardCalledThisRound)
+	m.ConsecutiveMissedRewardRounds.Set(m.orchInfo.ConsecutiveMissedRewardRounds)
+	m.StakeRank.Set(m.orchInfo.StakeRank)
+	m.ActiveSetCutoff.Set(m.orchInfo.ActiveSetCutoff)
+	m.ActiveSetMargin.Set(m.orchInfo.ActiveSetMargin)
 }
 
 // NewOrchInfoExporter creates a new OrchInfoExporter.
-func NewOrchInfoExporter(orchAddress string, fetchInterval time.Duration, updateInterval time.Duration, orchAddrSecondary string) *OrchInfoExporter {
+func NewOrchInfoExporter(orchAddress string, fetchInterval time.Duration, updateInterval time.Duration, orchAddrSecondary string, aprLookbackRounds []int, poolHistoryRounds int, rewardCallWindows []int, cryptoPricesExporter *crypto_prices_exporter.CryptoPricesExporter) *OrchInfoExporter {
 	exporter := &OrchInfoExporter{
+		aprLookbackRounds:    aprLookbackRounds,
+		poolHistoryRounds:    poolHistoryRounds,
+		rewardCallWindows:    rewardCallWindows,
+		cryptoPricesExporter: cryptoPricesExporter,
+		orchAddress:          orchAddress,
 		fetchInterval:        fetchInterval,
 		updateInterval:       updateInterval,
 		orchAddressSecondary: orchAddrSecondary,
 		orchInfoEndpoint:     orchInfoEndpoint,
-		orchInfoGraphqlQuery: fmt.Sprintf(graphqlQueryTemplate, orchAddress, orchAddrSecondary),
-		transcoderResponse:   &transcoderResponse{},
-		orchInfo:             &orchInfo{},
+		orchInfoGraphqlQuery: GetGraphqlQuery(orchAddress, orchAddrSecondary),
+		currentRoundEndpoint: currentRoundEndpoint,
+		transcoderResponse:   &TranscoderResponse{},
+		currentRoundResponse: &currentRoundResponse{},
+		orchInfo:             &OrchInfo{},
 	}
 
 	// Create request headers.
@@ -412,12 +910,17 @@ func NewOrchInfoExporter(orchAddress string, fetchInterval time.Duration, update
 		"X-Device-ID": {fmt.Sprintf(constants.ClientIDTemplate, orchAddress)},
 	}
 
-	// Initialize fetcher.
+	// Initialize fetchers.
 	exporter.orchInfoFetcher = fetcher.Fetcher{
 		URL:     exporter.orchInfoEndpoint,
 		Data:    &exporter.transcoderResponse,
 		Headers: headers,
 	}
+	exporter.currentRoundFetcher = fetcher.Fetcher{
+		URL:     exporter.currentRoundEndpoint,
+		Data:    &exporter.currentRoundResponse,
+		Headers: headers,
+	}
 
 	// Initialize metrics.
 	exporter.initMetrics()
@@ -426,10 +929,57 @@ func NewOrchInfoExporter(orchAddress string, fetchInterval time.Duration, update
 	return exporter
 }
 
+// OrchInfo returns the latest parsed orchestrator info.
+func (m *OrchInfoExporter) OrchInfo() OrchInfo {
+	m.transcoderResponse.Mutex.Lock()
+	defer m.transcoderResponse.Mutex.Unlock()
+	return *m.orchInfo
+}
+
+// PoolEarnings returns the LPT rewards and ETH fees earned by the orchestrator's pool in the given
+// number of completed rounds before the current round, together with the number of these rounds in
+// which the orchestrator was activated.
+func (m *OrchInfoExporter) PoolEarnings(window int) (rewards float64, fees float64, rounds int) {
+	m.transcoderResponse.Mutex.Lock()
+	defer m.transcoderResponse.Mutex.Unlock()
+	return getPoolEarnings(m.transcoderResponse.Data.Transcoder.Pools, int(m.orchInfo.CurrentRound), int(m.orchInfo.ActivationRound), window)
+}
+
+// MissedRewardRounds represents the rounds in which the orchestrator did not claim rewards.
+type MissedRewardRounds struct {
+	ActivationRound int   `json:"activationRound"` // The round the orchestrator activated.
+	CurrentRound    int   `json:"currentRound"`    // The current round.
+	FirstRound      int   `json:"firstRound"`      // The first round that was checked.
+	MissedRounds    []int `json:"missedRounds"`    // The rounds without a reward claim, sorted ascending.
+}
+
+// MissedRewardRounds returns the rounds since activation in which the orchestrator did not claim rewards.
+func (m *OrchInfoExporter) MissedRewardRounds() MissedRewardRounds {
+	m.transcoderResponse.Mutex.Lock()
+	defer m.transcoderResponse.Mutex.Unlock()
+	currentRound, activationRound := int(m.orchInfo.CurrentRound), int(m.orchInfo.ActivationRound)
+	firstRound, missedRounds := getMissedRewardRounds(m.transcoderResponse.Data.Transcoder.Pools, currentRound, activationRound)
+	return MissedRewardRounds{
+		ActivationRound: activationRound,
+		CurrentRound:    currentRound,
+		FirstRound:      firstRound,
+		MissedRounds:    missedRounds,
+	}
+}
+
+// ServeMissedRewardRounds serves the rounds in which the orchestrator did not claim rewards as JSON.
+func (m *OrchInfoExporter) ServeMissedRewardRounds(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(m.MissedRewardRounds()); err != nil {
+		log.Printf("Error encoding missed reward rounds: %v", err)
+	}
+}
+
 // Start starts the OrchInfoExporter.
 func (m *OrchInfoExporter) Start() {
 	// Fetch initial data and update metrics.
 	m.orchInfoFetcher.FetchGraphQLData(m.orchInfoGraphqlQuery)
+	m.currentRoundFetcher.FetchData()
 	m.updateMetrics()
 
 	// Start fetchers in a goroutine.
@@ -441,6 +991,10 @@ func (m *OrchInfoExporter) Start() {
 			m.transcoderResponse.Mutex.Lock()
 			m.orchInfoFetcher.FetchGraphQLData(m.orchInfoGraphqlQuery)
 			m.transcoderResponse.Mutex.Unlock()
+
+			m.currentRoundResponse.Mutex.Lock()
+			m.currentRoundFetcher.FetchData()
+			m.currentRoundResponse.Mutex.Unlock()
 		}
 	}()
 
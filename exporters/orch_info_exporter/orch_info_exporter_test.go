@@ -0,0 +1,148 @@
+package orch_info_exporter
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// newPool returns a pool of the given round with the given minted reward tokens.
+func newPool(round int, rewardTokens string) pool {
+	var p pool
+	p.Round.ID = strconv.Itoa(round)
+	p.RewardTokens = rewardTokens
+	return p
+}
+
+func TestGetMissedRewardRounds(t *testing.T) {
+	// The pool limit is reached with one pool per round before round 2000, of which round 1500 was missed.
+	var limitPools []pool
+	for round := 2000 - PoolsLimit; round < 2000; round++ {
+		rewardTokens := "1"
+		if round == 1500 {
+			rewardTokens = "0"
+		}
+		limitPools = append(limitPools, newPool(round, rewardTokens))
+	}
+
+	tests := []struct {
+		name            string
+		pools           []pool
+		currentRound    int
+		activationRound int
+		wantFirstRound  int
+		wantMissed      []int
+	}{
+		{
+			name:            "no pools",
+			currentRound:    13,
+			activationRound: 10,
+			wantFirstRound:  10,
+			wantMissed:      []int{10, 11, 12},
+		},
+		{
+			name:            "activated in the current round",
+			currentRound:    10,
+			activationRound: 10,
+			wantFirstRound:  10,
+			wantMissed:      []int{},
+		},
+		{
+			name:            "current round is not included",
+			pools:           []pool{newPool(10, "1"), newPool(11, "0"), newPool(12, "1")},
+			currentRound:    14,
+			activationRound: 10,
+			wantFirstRound:  10,
+			wantMissed:      []int{11, 13},
+		},
+		{
+			name:            "pool limit reached",
+			pools:           limitPools,
+			currentRound:    2000,
+			activationRound: 1,
+			wantFirstRound:  2000 - PoolsLimit,
+			wantMissed:      []int{1500},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			firstRound, missed := getMissedRewardRounds(tt.pools, tt.currentRound, tt.activationRound)
+			if firstRound != tt.wantFirstRound || !reflect.DeepEqual(missed, tt.wantMissed) {
+				t.Errorf("getMissedRewardRounds() = (%v, %v), want (%v, %v)", firstRound, missed, tt.wantFirstRound, tt.wantMissed)
+			}
+		})
+	}
+}
+
+func TestGetActiveSetPosition(t *testing.T) {
+	transcoders := []activeTranscoder{
+		{ID: "0xa", TotalStake: "3000"},
+		{ID: "0xb", TotalStake: "2000"},
+		{ID: "0xc", TotalStake: "1000"},
+	}
+	challengers := []activeTranscoder{{ID: "0xd", TotalStake: "800"}}
+
+	tests := []struct {
+		name        string
+		transcoders []activeTranscoder
+		challengers []activeTranscoder
+		orchAddress string
+		orchStake   float64
+		wantRank    int
+		wantCutoff  float64
+		wantMargin  float64
+	}{
+		{
+			name:        "no active set",
+			orchAddress: "0xa",
+			orchStake:   3000,
+		},
+		{
+			name:        "top of the active set",
+			transcoders: transcoders,
+			challengers: challengers,
+			orchAddress: "0xa",
+			orchStake:   3000,
+			wantRank:    1,
+			wantCutoff:  1000,
+			wantMargin:  2200,
+		},
+		{
+			name:        "last of the active set",
+			transcoders: transcoders,
+			challengers: challengers,
+			orchAddress: "0xc",
+			orchStake:   1000,
+			wantRank:    3,
+			wantCutoff:  1000,
+			wantMargin:  200,
+		},
+		{
+			name:        "no challenger",
+			transcoders: transcoders,
+			orchAddress: "0xb",
+			orchStake:   2000,
+			wantRank:    2,
+			wantCutoff:  1000,
+			wantMargin:  2000,
+		},
+		{
+			name:        "not active",
+			transcoders: transcoders,
+			challengers: challengers,
+			orchAddress: "0xd",
+			orchStake:   800,
+			wantRank:    0,
+			wantCutoff:  1000,
+			wantMargin:  -200,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rank, cutoff, margin := getActiveSetPosition(tt.transcoders, tt.challengers, tt.orchAddress, tt.orchStake)
+			if rank != tt.wantRank || cutoff != tt.wantCutoff || margin != tt.wantMargin {
+				t.Errorf("getActiveSetPosition() = (%v, %v, %v), want (%v, %v, %v)", rank, cutoff, margin, tt.wantRank, tt.wantCutoff, tt.wantMargin)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+// Package orch_profit_exporter implements a Livepeer orchestrator profit exporter that combines the data
+// of the orch_tickets_exporter, orch_rewards_exporter and orch_info_exporter and exposes the
+// orchestrator's net profit via Prometheus metrics. The gas costs are taken from the transaction receipts
+// of the receipts provider. All ETH amounts are expressed in ETH and all LPT amounts in LPT.
+package orch_profit_exporter
+
+import (
+	"livepeer-exporter/exporters/orch_info_exporter"
+	"livepeer-exporter/exporters/orch_rewards_exporter"
+	"livepeer-exporter/exporters/orch_tickets_exporter"
+	"livepeer-exporter/receipts"
+	"livepeer-exporter/util"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// periodProfit represents the profit of the orchestrator over a single period.
+type periodProfit struct {
+	Fees          float64 // The ETH fees won by the orchestrator.
+	Rewards       float64 // The LPT rewards claimed by the orchestrator.
+	TicketGasCost float64 // The gas cost of the ticket redemptions in ETH.
+	RewardGasCost float64 // The gas cost of the reward calls in ETH.
+}
+
+// OrchProfitExporter combines the orchestrator's fees, rewards, gas costs and cuts and exposes the
+// orchestrator's profit via Prometheus.
+type OrchProfitExporter struct {
+	// Metrics.
+	GasCost       *prometheus.GaugeVec
+	NetProfitETH  *prometheus.GaugeVec
+	NetRewardsLPT *prometheus.GaugeVec
+	OwnProfitETH  *prometheus.GaugeVec
+	OwnRewardsLPT *prometheus.GaugeVec
+
+	// Config settings.
+	updateInterval time.Duration // How often to update metrics.
+
+	// Data sources.
+	receiptProvider     *receipts.ReceiptProvider                  // Provides the gas costs of the transactions.
+	orchInfoExporter    *orch_info_exporter.OrchInfoExporter       // Provides the orchestrator's stake and cuts.
+	orchTicketsExporter *orch_tickets_exporter.OrchTicketsExporter // Provides the orchestrator's fees.
+	orchRewardsExporter *orch_rewards_exporter.OrchRewardsExporter // Provides the orchestrator's rewards.
+}
+
+// initMetrics initializes the orchestrator profit metrics.
+func (m *OrchProfitExporter) initMetrics() {
+	m.GasCost = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_gas_cost_eth",
+			Help: "The gas cost of all ticket redemptions and reward calls per period in ETH.",
+		},
+		[]string{"period"},
+	)
+	m.NetProfitETH = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_net_profit_eth",
+			Help: "The ETH fees minus the ticket redemption and reward call gas costs per period in ETH.",
+		},
+		[]string{"period"},
+	)
+	m.NetRewardsLPT = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_net_rewards_lpt",
+			Help: "The LPT rewards claimed by the orchestrator per period in LPT.",
+		},
+		[]string{"period"},
+	)
+	m.OwnProfitETH = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_own_profit_eth",
+			Help: "The orchestrator's share of the ETH fees minus the gas costs per period in ETH.",
+		},
+		[]string{"period"},
+	)
+	m.OwnRewardsLPT = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_own_rewards_lpt",
+			Help: "The orchestrator's share of the LPT rewards per period in LPT.",
+		},
+		[]string{"period"},
+	)
+}
+
+// registerMetrics registers the orchestrator profit metrics with Prometheus.
+func (m *OrchProfitExporter) registerMetrics() {
+	prometheus.MustRegister(
+		m.GasCost,
+		m.NetProfitETH,
+		m.NetRewardsLPT,
+		m.OwnProfitETH,
+		m.OwnRewardsLPT,
+	)
+}
+
+// getPeriodProfits aggregates the orchestrator's fees, rewards and gas costs per period. The returned
+// bool is false when the winning tickets, the reward events or the receipts of their transactions were
+// not all fetched yet.
+func (m *OrchProfitExporter) getPeriodProfits(periods []util.Period) (map[string]*periodProfit, bool) {
+	if !m.orchTicketsExporter.Complete() || !m.orchRewardsExporter.Complete() {
+		return nil, false
+	}
+	tickets := m.orchTicketsExporter.Tickets()
+	rewards := m.orchRewardsExporter.Rewards()
+	ids := make([]string, 0, len(tickets)+len(rewards))
+	for _, ticket := range tickets {
+		ids = append(ids, ticket.ID)
+	}
+	for _, reward := range rewards {
+		ids = append(ids, reward.ID)
+	}
+	gasCosts, ok := m.receiptProvider.GetGasCosts(ids)
+	if !ok {
+		return nil, false
+	}
+
+	profits := make(map[string]*periodProfit, len(periods))
+	for _, period := range periods {
+		profits[period.Name] = &periodProfit{}
+	}
+
+	for _, ticket := range tickets {
+		for _, period := range periods {
+			if !ticket.Timestamp.Before(period.Start) {
+				profits[period.Name].Fees += ticket.FaceValue
+				profits[period.Name].TicketGasCost += gasCosts[ticket.ID]
+			}
+		}
+	}
+	for _, reward := range rewards {
+		for _, period := range periods {
+			if !reward.Timestamp.Before(period.Start) {
+				profits[period.Name].Rewards += reward.RewardTokens
+				profits[period.Name].RewardGasCost += gasCosts[reward.ID]
+			}
+		}
+	}
+
+	return profits, true
+}
+
+// updateMetrics updates the metrics with the data of the tickets, rewards and info exporters.
+func (m *OrchProfitExporter) updateMetrics() {
+	orchInfo := m.orchInfoExporter.OrchInfo()
+
+	// Calculate the share of the delegator pool that is owned by the orchestrator.
+	var ownPoolShare float64
+	if orchInfo.TotalStake > 0 {
+		ownPoolShare = orchInfo.OrchStake / orchInfo.TotalStake
+	}
+
+	periods := util.GetPeriods(time.Now())
+	profits, ok := m.getPeriodProfits(periods)
+	if !ok {
+		return
+	}
+	for _, period := range periods {
+		profit := profits[period.Name]
+		gasCost := profit.TicketGasCost + profit.RewardGasCost
+
+		// The orchestrator keeps its cut and receives a share of the delegator pool for its own stake.
+		ownFees := profit.Fees*orchInfo.FeeCut + profit.Fees*(1-orchInfo.FeeCut)*ownPoolShare
+		ownRewards := profit.Rewards*orchInfo.RewardCut + profit.Rewards*(1-orchInfo.RewardCut)*ownPoolShare
+
+		m.GasCost.WithLabelValues(period.Name).Set(gasCost)
+		m.NetProfitETH.WithLabelValues(period.Name).Set(profit.Fees - gasCost)
+		m.NetRewardsLPT.WithLabelValues(period.Name).Set(profit.Rewards)
+		m.OwnProfitETH.WithLabelValues(period.Name).Set(ownFees - gasCost)
+		m.OwnRewardsLPT.WithLabelValues(period.Name).Set(ownRewards)
+	}
+}
+
+// NewOrchProfitExporter creates a new OrchProfitExporter.
+func NewOrchProfitExporter(updateInterval time.Duration, receiptProvider *receipts.ReceiptProvider, orchInfoExporter *orch_info_exporter.OrchInfoExporter, orchTicketsExporter *orch_tickets_exporter.OrchTicketsExporter, orchRewardsExporter *orch_rewards_exporter.OrchRewardsExporter) *OrchProfitExporter {
+	exporter := &OrchProfitExporter{
+		updateInterval:      updateInterval,
+		receiptProvider:     receiptProvider,
+		orchInfoExporter:    orchInfoExporter,
+		orchTicketsExporter: orchTicketsExporter,
+		orchRewardsExporter: orchRewardsExporter,
+	}
+
+	// Initialize metrics.
+	exporter.initMetrics()
+	exporter.registerMetrics()
+
+	return exporter
+}
+
+// Start starts the OrchProfitExporter.
+func (m *OrchProfitExporter) Start() {
+	// Update initial metrics.
+	m.updateMetrics()
+
+	// Start metrics updater in a goroutine.
+	go func() {
+		ticker := time.NewTicker(m.updateInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			m.updateMetrics()
+		}
+	}()
+}
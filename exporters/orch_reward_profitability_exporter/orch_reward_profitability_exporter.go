@@ -0,0 +1,261 @@
+// Package orch_reward_profitability_exporter implements a Livepeer orchestrator reward profitability exporter
+// that compares the fiat value of the LPT minted by the reward calls of the orch_rewards_exporter with their
+// fiat gas cost, using the transaction receipts of the receipts provider and the prices of the day of each
+// reward call, and estimates the stake at which a reward call breaks even at the current gas price of the
+// orch_gas_exporter and the current prices of the crypto_prices_exporter. The results are exposed via
+// Prometheus metrics.
+package orch_reward_profitability_exporter
+
+import (
+	"livepeer-exporter/exporters/crypto_prices_exporter"
+	"livepeer-exporter/exporters/orch_gas_exporter"
+	"livepeer-exporter/exporters/orch_rewards_exporter"
+	"livepeer-exporter/prices"
+	"livepeer-exporter/receipts"
+	"livepeer-exporter/util"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// gasEstimateRewardCalls is the number of latest reward calls of which the gas used is averaged to estimate
+// the gas used by the next reward call.
+const gasEstimateRewardCalls = 10
+
+// currencies are the fiat currencies in which the reward calls are valued.
+var currencies = []string{"USD", "EUR"}
+
+// ratioCurrency is the fiat currency in which the values of the reward calls are summed to calculate the
+// profitability ratio.
+const ratioCurrency = "USD"
+
+// rewardCall represents a reward call with the gas used and gas cost from its transaction receipt.
+type rewardCall struct {
+	orch_rewards_exporter.Reward
+	ReceiptGasUsed float64 // The gas used according to the transaction receipt.
+	ReceiptGasCost float64 // The gas cost according to the transaction receipt in ETH.
+}
+
+// getAverageGasUsed returns the average gas used by the latest reward calls.
+func getAverageGasUsed(rewardCalls []rewardCall) float64 {
+	sort.Slice(rewardCalls, func(i, j int) bool {
+		return rewardCalls[i].Timestamp.After(rewardCalls[j].Timestamp)
+	})
+	if len(rewardCalls) > gasEstimateRewardCalls {
+		rewardCalls = rewardCalls[:gasEstimateRewardCalls]
+	}
+	gasUsed := make([]float64, 0, len(rewardCalls))
+	for _, rewardCall := range rewardCalls {
+		gasUsed = append(gasUsed, rewardCall.ReceiptGasUsed)
+	}
+	return util.Mean(gasUsed)
+}
+
+// OrchRewardProfitabilityExporter compares the value of the orchestrator's rewards with their gas costs and
+// exposes the results via Prometheus.
+type OrchRewardProfitabilityExporter struct {
+	// Metrics.
+	RewardCallValue        *prometheus.GaugeVec
+	RewardCallGasCostValue *prometheus.GaugeVec
+	RewardCallNetValue     *prometheus.GaugeVec
+	ProfitabilityRatio     *prometheus.GaugeVec
+	EstimatedGasCost       prometheus.Gauge
+	BreakEvenStake         prometheus.Gauge
+
+	// Config settings.
+	updateInterval time.Duration // How often to update metrics.
+
+	// Data sources.
+	receiptProvider      *receipts.ReceiptProvider                    // Provides the transaction receipts.
+	priceProvider        *prices.HistoricalPriceProvider              // Provides the prices of the day of each reward call.
+	orchRewardsExporter  *orch_rewards_exporter.OrchRewardsExporter   // Provides the orchestrator's reward calls and the mint rate.
+	orchGasExporter      *orch_gas_exporter.OrchGasExporter           // Provides the current gas price.
+	cryptoPricesExporter *crypto_prices_exporter.CryptoPricesExporter // Provides the LPT and ETH prices.
+}
+
+// initMetrics initializes the reward profitability metrics.
+func (m *OrchRewardProfitabilityExporter) initMetrics() {
+	m.RewardCallValue = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_reward_call_value",
+			Help: "The fiat value of the LPT minted by each reward call at the prices of the day of the call.",
+		},
+		[]string{"id", "currency"},
+	)
+	m.RewardCallGasCostValue = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_reward_call_gas_cost_value",
+			Help: "The fiat value of the gas cost of each reward call at the prices of the day of the call.",
+		},
+		[]string{"id", "currency"},
+	)
+	m.RewardCallNetValue = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_reward_call_net_value",
+			Help: "The fiat value of the LPT minted by each reward call minus the fiat value of its gas cost.",
+		},
+		[]string{"id", "currency"},
+	)
+	m.ProfitabilityRatio = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_reward_profitability_ratio",
+			Help: "The value of the LPT minted by the reward calls divided by the value of their gas cost per period.",
+		},
+		[]string{"period"},
+	)
+	m.EstimatedGasCost = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_reward_call_estimated_gas_cost_eth",
+			Help: "The estimated gas cost of the next reward call at the current gas price in ETH.",
+		},
+	)
+	m.BreakEvenStake = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_reward_break_even_stake",
+			Help: "The total stake in LPT at which the value of the LPT minted by a reward call equals its gas cost at the current gas price.",
+		},
+	)
+}
+
+// registerMetrics registers the reward profitability metrics with Prometheus.
+func (m *OrchRewardProfitabilityExporter) registerMetrics() {
+	prometheus.MustRegister(
+		m.RewardCallValue,
+		m.RewardCallGasCostValue,
+		m.RewardCallNetValue,
+		m.ProfitabilityRatio,
+		m.EstimatedGasCost,
+		m.BreakEvenStake,
+	)
+}
+
+// getRewardCalls returns the reward calls of which the transaction receipt was fetched. The gas used
+// reported by the subgraph is not used since it holds the gas limit.
+func (m *OrchRewardProfitabilityExporter) getRewardCalls() []rewardCall {
+	var rewardCalls []rewardCall
+	for _, reward := range m.orchRewardsExporter.Rewards() {
+		receipt, ok := m.receiptProvider.GetReceipt(reward.ID)
+		if !ok {
+			continue
+		}
+		rewardCalls = append(rewardCalls, rewardCall{Reward: reward, ReceiptGasUsed: receipt.GasUsed, ReceiptGasCost: receipt.GasCost()})
+	}
+	return rewardCalls
+}
+
+// getRewardCallValues returns the fiat value of the LPT minted by the reward call and of its gas cost at
+// the prices of the day of the reward call.
+func (m *OrchRewardProfitabilityExporter) getRewardCallValues(rewardCall rewardCall, currency string) (value float64, gasCostValue float64, err error) {
+	lptPrice, err := m.priceProvider.GetPrice("LPT", currency, rewardCall.Timestamp)
+	if err != nil {
+		return 0, 0, err
+	}
+	ethPrice, err := m.priceProvider.GetPrice("ETH", currency, rewardCall.Timestamp)
+	if err != nil {
+		return 0, 0, err
+	}
+	return rewardCall.RewardTokens * lptPrice, rewardCall.ReceiptGasCost * ethPrice, nil
+}
+
+// updateMetrics updates the metrics with the data of the rewards, gas and crypto prices exporters.
+func (m *OrchRewardProfitabilityExporter) updateMetrics() {
+	rewardCalls := m.getRewardCalls()
+
+	// Reset the metrics so that no stale values remain.
+	m.RewardCallValue.Reset()
+	m.RewardCallGasCostValue.Reset()
+	m.RewardCallNetValue.Reset()
+	m.ProfitabilityRatio.Reset()
+
+	// Set the values of each reward call and sum them per period.
+	periods := util.GetPeriods(time.Now())
+	periodValues := make(map[string]float64, len(periods))
+	periodGasCostValues := make(map[string]float64, len(periods))
+	for _, currency := range currencies {
+		for _, rewardCall := range rewardCalls {
+			value, gasCostValue, err := m.getRewardCallValues(rewardCall, currency)
+			if err != nil {
+				log.Printf("Error valuing reward call '%s' in %s: %v", rewardCall.ID, currency, err)
+				continue
+			}
+			m.RewardCallValue.WithLabelValues(rewardCall.ID, currency).Set(value)
+			m.RewardCallGasCostValue.WithLabelValues(rewardCall.ID, currency).Set(gasCostValue)
+			m.RewardCallNetValue.WithLabelValues(rewardCall.ID, currency).Set(value - gasCostValue)
+
+			if currency != ratioCurrency {
+				continue
+			}
+			for _, period := range periods {
+				if !rewardCall.Timestamp.Before(period.Start) {
+					periodValues[period.Name] += value
+					periodGasCostValues[period.Name] += gasCostValue
+				}
+			}
+		}
+	}
+	if err := m.priceProvider.Save(); err != nil {
+		log.Printf("Error saving price cache: %v", err)
+	}
+
+	// Set the profitability ratio per period.
+	for _, period := range periods {
+		if periodGasCostValues[period.Name] > 0 {
+			m.ProfitabilityRatio.WithLabelValues(period.Name).Set(periodValues[period.Name] / periodGasCostValues[period.Name])
+		}
+	}
+
+	// The break-even stake only depends on the current ETH/LPT price ratio.
+	prices := m.cryptoPricesExporter.CryptoPrices()
+	if prices.LPTUSDPrice == 0 {
+		return
+	}
+	ethLPTRatio := prices.ETHUSDPrice / prices.LPTUSDPrice
+
+	// Estimate the stake at which the minted LPT covers the gas cost of the next reward call. The estimate
+	// is skipped until the gas price was fetched.
+	gasPrice := m.orchGasExporter.GasPrice()
+	if gasPrice == 0 {
+		return
+	}
+	estimatedGasCost := getAverageGasUsed(rewardCalls) * gasPrice / 1e18
+	m.EstimatedGasCost.Set(estimatedGasCost)
+	if mintRate := m.orchRewardsExporter.MintRate(); mintRate > 0 {
+		m.BreakEvenStake.Set(estimatedGasCost * ethLPTRatio / mintRate)
+	}
+}
+
+// NewOrchRewardProfitabilityExporter creates a new OrchRewardProfitabilityExporter.
+func NewOrchRewardProfitabilityExporter(updateInterval time.Duration, receiptProvider *receipts.ReceiptProvider, priceProvider *prices.HistoricalPriceProvider, orchRewardsExporter *orch_rewards_exporter.OrchRewardsExporter, orchGasExporter *orch_gas_exporter.OrchGasExporter, cryptoPricesExporter *crypto_prices_exporter.CryptoPricesExporter) *OrchRewardProfitabilityExporter {
+	exporter := &OrchRewardProfitabilityExporter{
+		updateInterval:       updateInterval,
+		receiptProvider:      receiptProvider,
+		priceProvider:        priceProvider,
+		orchRewardsExporter:  orchRewardsExporter,
+		orchGasExporter:      orchGasExporter,
+		cryptoPricesExporter: cryptoPricesExporter,
+	}
+
+	// Initialize metrics.
+	exporter.initMetrics()
+	exporter.registerMetrics()
+
+	return exporter
+}
+
+// Start starts the OrchRewardProfitabilityExporter.
+func (m *OrchRewardProfitabilityExporter) Start() {
+	// Update initial metrics.
+	m.updateMetrics()
+
+	// Start metrics updater in a goroutine.
+	go func() {
+		ticker := time.NewTicker(m.updateInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			m.updateMetrics()
+		}
+	}()
+}
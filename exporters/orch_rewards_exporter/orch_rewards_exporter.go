@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"livepeer-exporter/constants"
 	"livepeer-exporter/fetcher"
+	"livepeer-exporter/util"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
@@ -18,10 +20,15 @@ var (
 	rewardEventsEndpoint = constants.LivePeerSubgraphEndpoint
 )
 
-// graphqlQuery represents the GraphQL query to fetch data from the GraphQL API.
-const graphqlQueryTemplate = `
+// inflationDivisor is the divisor of the inflation rate stored in the Livepeer subgraph.
+const inflationDivisor = 1e9
+
+// rewardEventsQueryTemplate represents the GraphQL query to fetch a page of reward events from the GraphQL
+// API. The events are paginated by ID since the subgraph only returns a limited number of events per query.
+const rewardEventsQueryTemplate = `
 {
-	rewardEvents(where: {delegate: "%s"}) {
+	rewardEvents(where: {delegate: "%s", id_gt: "%s"}, orderBy: id, orderDirection: asc, first: %d) {
+		id
 		transaction {
 			gasUsed
 			gasPrice
@@ -37,8 +44,39 @@ const graphqlQueryTemplate = `
 }
 `
 
+// poolsQueryTemplate represents the GraphQL query to fetch a page of pools from the GraphQL API. The pools
+// are paginated by ID like the reward events.
+const poolsQueryTemplate = `
+{
+	pools(where: {delegate: "%s", id_gt: "%s"}, orderBy: id, orderDirection: asc, first: %d) {
+		id
+		rewardTokens
+		totalStake
+		round {
+			id
+			startTimestamp
+			inflation
+			totalSupply
+			totalActiveStake
+		}
+	}
+}
+`
+
+// protocolQuery represents the GraphQL query to fetch the current round from the GraphQL API.
+const protocolQuery = `
+{
+	protocol(id: "0") {
+		currentRound {
+			id
+		}
+	}
+}
+`
+
 // rewardEvent represents the structure of the rewardEvent field contained in the GraphQL API response.
 type rewardEvent struct {
+	ID          string
 	Transaction struct {
 		GasUsed     string
 		GasPrice    string
@@ -52,6 +90,20 @@ type rewardEvent struct {
 	RewardTokens string
 }
 
+// pool represents the structure of the pools field contained in the GraphQL API response.
+type pool struct {
+	ID           string
+	RewardTokens string
+	TotalStake   string
+	Round        struct {
+		ID               string
+		StartTimestamp   string
+		Inflation        string
+		TotalSupply      string
+		TotalActiveStake string
+	}
+}
+
 // rewardEventResponse represents the structure of the GraphQL API response.
 type rewardEventResponse struct {
 	sync.Mutex
@@ -59,7 +111,83 @@ type rewardEventResponse struct {
 	// Response data.
 	Data struct {
 		RewardEvents []rewardEvent
+		Pools        []pool
+		Protocol     struct {
+			CurrentRound struct {
+				ID string
+			}
+		}
 	}
+
+	// Whether all reward events were fetched.
+	Complete bool
+}
+
+// Reward represents a parsed reward event.
+type Reward struct {
+	ID           string    // The transaction hash of the reward call.
+	Timestamp    time.Time // The block time of the reward call.
+	BlockNumber  float64   // The block number of the reward call.
+	Round        float64   // The round in which the reward was claimed.
+	RewardTokens float64   // The amount of LPT minted by the reward call.
+	GasUsed      float64   // The gas used by the reward call.
+	GasPrice     float64   // The gas price of the reward call in Wei.
+	GasCost      float64   // The gas cost of the reward call in ETH.
+}
+
+// parseReward parses a rewardEvent into a Reward.
+func parseReward(event rewardEvent) Reward {
+	reward := Reward{
+		ID:        event.Transaction.ID,
+		Timestamp: time.Unix(int64(event.Transaction.Timestamp), 0),
+	}
+	reward.RewardTokens, _ = strconv.ParseFloat(event.RewardTokens, 64)
+	reward.GasUsed, _ = strconv.ParseFloat(event.Transaction.GasUsed, 64)
+	reward.GasPrice, _ = strconv.ParseFloat(event.Transaction.GasPrice, 64)
+	reward.GasCost = (reward.GasUsed * reward.GasPrice) / 1e18
+	reward.BlockNumber, _ = strconv.ParseFloat(event.Transaction.BlockNumber, 64)
+	reward.Round, _ = strconv.ParseFloat(event.Round.ID, 64)
+	return reward
+}
+
+// roundReward represents the expected and actual reward of the orchestrator in a round.
+type roundReward struct {
+	Round     string    // The round ID.
+	StartTime time.Time // The start time of the round.
+	Expected  float64   // The amount of LPT the orchestrator should have minted.
+	Actual    float64   // The amount of LPT the orchestrator minted.
+}
+
+// getRoundRewards returns the expected and actual reward of the orchestrator in each round it was active.
+// The current round is skipped until the reward is claimed since the reward can still be claimed. The
+// mintable tokens of a round are the total supply times the inflation rate, which are distributed
+// proportionally to the total stake of the active orchestrators.
+func getRoundRewards(pools []pool, currentRound string) []roundReward {
+	roundRewards := make([]roundReward, 0, len(pools))
+	for _, pool := range pools {
+		var actual, totalStake, inflation, totalSupply, totalActiveStake, startTimestamp float64
+		util.SetFloatFromStr(&actual, pool.RewardTokens)
+		if pool.Round.ID == currentRound && actual == 0 {
+			continue
+		}
+		util.SetFloatFromStr(&totalStake, pool.TotalStake)
+		util.SetFloatFromStr(&inflation, pool.Round.Inflation)
+		util.SetFloatFromStr(&totalSupply, pool.Round.TotalSupply)
+		util.SetFloatFromStr(&totalActiveStake, pool.Round.TotalActiveStake)
+		util.SetFloatFromStr(&startTimestamp, pool.Round.StartTimestamp)
+
+		var expected float64
+		if totalActiveStake > 0 {
+			expected = totalSupply * inflation / inflationDivisor * totalStake / totalActiveStake
+		}
+		roundRewards = append(roundRewards, roundReward{
+			Round:     pool.Round.ID,
+			StartTime: time.Unix(int64(startTimestamp), 0),
+			Expected:  expected,
+			Actual:    actual,
+		})
+	}
+	return roundRewards
 }
 
 // OrchRewardsExporter fetches data from the API and exposes orchestrator's rewards metrics via Prometheus.
@@ -85,12 +213,18 @@ type OrchRewardsExporter struct {
 	YearGasCost       prometheus.Gauge
 	TotalGasCost      prometheus.Gauge
 
+	// Reward validation metrics.
+	RoundExpectedReward   *prometheus.GaugeVec
+	RoundRewardDifference *prometheus.GaugeVec
+	ExpectedRewards       *prometheus.GaugeVec
+	RewardDifference      *prometheus.GaugeVec
+
 	// Config settings.
-	orchAddress             string        // The orchestrator address to filter rewards by.
-	fetchInterval           time.Duration // How often to fetch data.
-	updateInterval          time.Duration // How often to update metrics.
-	orchRewardsEndpoint     string        // The endpoint to fetch data from.
-	orchRewardsGraphqlQuery string        // The GraphQL query to fetch data from the GraphQL API.
+	orchAddress         string        // The orchestrator address to filter rewards by.
+	fetchInterval       time.Duration // How often to fetch data.
+	updateInterval      time.Duration // How often to update metrics.
+	poolHistoryRounds   int           // The number of rounds of per-round reward validation metrics to expose.
+	orchRewardsEndpoint string        // The endpoint to fetch data from.
 
 	// Data.
 	orchRewards *rewardEventResponse // The data returned by the API.
@@ -222,6 +356,34 @@ func (m *OrchRewardsExporter) initMetrics() {
 			Help: "Total gas cost for all reward transactions in Gwei.",
 		},
 	)
+	m.RoundExpectedReward = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_round_expected_reward",
+			Help: "The amount of LPT the orchestrator should have minted in each round.",
+		},
+		[]string{"round"},
+	)
+	m.RoundRewardDifference = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_round_reward_difference",
+			Help: "The difference between the minted and the expected LPT rewards in each round.",
+		},
+		[]string{"round"},
+	)
+	m.ExpectedRewards = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_expected_rewards",
+			Help: "The amount of LPT the orchestrator should have minted per period.",
+		},
+		[]string{"period"},
+	)
+	m.RewardDifference = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_reward_difference",
+			Help: "The difference between the minted and the expected LPT rewards per period.",
+		},
+		[]string{"period"},
+	)
 }
 
 // registerMetrics registers the orchestrator rewards metrics with Prometheus.
@@ -246,6 +408,10 @@ func (m *OrchRewardsExporter) registerMetrics() {
 		m.YearGasCost,
 		m.RewardRound,
 		m.TotalGasCost,
+		m.RoundExpectedReward,
+		m.RoundRewardDifference,
+		m.ExpectedRewards,
+		m.RewardDifference,
 	)
 }
 
@@ -263,22 +429,19 @@ func (m *OrchRewardsExporter) updateMetrics() {
 	var totalRewards, totalGasCost float64
 	var dayRewards, weekRewards, thirtyDayRewards, ninetyDayRewards, yearRewards float64
 	var dayGasCost, weekGasCost, thirtyDayGasCost, ninetyDayGasCost, yearGasCost float64
-	for _, reward := range m.orchRewards.Data.RewardEvents {
-		amount, _ := strconv.ParseFloat(reward.RewardTokens, 64)
-		gasUsed, _ := strconv.ParseFloat(reward.Transaction.GasUsed, 64)
-		gasPrice, _ := strconv.ParseFloat(reward.Transaction.GasPrice, 64)
-		gasCost := (gasUsed * gasPrice) / 1e9
-		blockNumber, _ := strconv.ParseFloat(reward.Transaction.BlockNumber, 64)
-		blockTime, _ := strconv.ParseFloat(strconv.Itoa(reward.Transaction.Timestamp), 64)
-		round, _ := strconv.ParseFloat(reward.Round.ID, 64)
-
-		m.RewardAmount.WithLabelValues(reward.Transaction.ID).Set(amount)
-		m.RewardGasUsed.WithLabelValues(reward.Transaction.ID).Set(gasUsed)
-		m.RewardGasPrice.WithLabelValues(reward.Transaction.ID).Set(gasPrice)
-		m.RewardGasCost.WithLabelValues(reward.Transaction.ID).Set(gasCost)
-		m.RewardBlockNumber.WithLabelValues(reward.Transaction.ID).Set(blockNumber)
-		m.RewardBlockTime.WithLabelValues(reward.Transaction.ID).Set(blockTime * 1000) // Grafana expects milliseconds.
-		m.RewardRound.WithLabelValues(reward.Transaction.ID).Set(round)
+	for _, event := range m.orchRewards.Data.RewardEvents {
+		reward := parseReward(event)
+		amount := reward.RewardTokens
+		gasCost := reward.GasCost * 1e9 // Expressed in Gwei.
+		blockTime := float64(reward.Timestamp.Unix())
+
+		m.RewardAmount.WithLabelValues(reward.ID).Set(amount)
+		m.RewardGasUsed.WithLabelValues(reward.ID).Set(reward.GasUsed)
+		m.RewardGasPrice.WithLabelValues(reward.ID).Set(reward.GasPrice)
+		m.RewardGasCost.WithLabelValues(reward.ID).Set(gasCost)
+		m.RewardBlockNumber.WithLabelValues(reward.ID).Set(reward.BlockNumber)
+		m.RewardBlockTime.WithLabelValues(reward.ID).Set(blockTime * 1000) // Grafana expects milliseconds.
+		m.RewardRound.WithLabelValues(reward.ID).Set(reward.Round)
 
 		// Calculate the rewards and gas costs for different periods.
 		if blockTime >= float64(dayAgo.Unix()) {
@@ -318,17 +481,49 @@ func (m *OrchRewardsExporter) updateMetrics() {
 	m.NinetyDayGasCost.Set(ninetyDayGasCost)
 	m.YearGasCost.Set(yearGasCost)
 	m.TotalGasCost.Set(totalGasCost)
+
+	// Compare the minted rewards with the expected rewards.
+	m.updateRewardValidationMetrics()
+}
+
+// updateRewardValidationMetrics compares the minted LPT rewards with the expected rewards per round and
+// period. A negative difference represents LPT lost due to missed or faulty reward calls. The per-round
+// metrics only cover the rounds of the pool history.
+func (m *OrchRewardsExporter) updateRewardValidationMetrics() {
+	currentRound, _ := strconv.Atoi(m.orchRewards.Data.Protocol.CurrentRound.ID)
+	roundRewards := getRoundRewards(m.orchRewards.Data.Pools, m.orchRewards.Data.Protocol.CurrentRound.ID)
+	m.RoundExpectedReward.Reset()
+	m.RoundRewardDifference.Reset()
+	for _, roundReward := range roundRewards {
+		if round, _ := strconv.Atoi(roundReward.Round); round <= currentRound-m.poolHistoryRounds {
+			continue
+		}
+		m.RoundExpectedReward.WithLabelValues(roundReward.Round).Set(roundReward.Expected)
+		m.RoundRewardDifference.WithLabelValues(roundReward.Round).Set(roundReward.Actual - roundReward.Expected)
+	}
+
+	for _, period := range util.GetPeriods(time.Now()) {
+		var expected, difference float64
+		for _, roundReward := range roundRewards {
+			if !roundReward.StartTime.Before(period.Start) {
+				expected += roundReward.Expected
+				difference += roundReward.Actual - roundReward.Expected
+			}
+		}
+		m.ExpectedRewards.WithLabelValues(period.Name).Set(expected)
+		m.RewardDifference.WithLabelValues(period.Name).Set(difference)
+	}
 }
 
 // NewOrchRewardsExporter creates a new OrchRewardsExporter.
-func NewOrchRewardsExporter(orchAddress string, fetchInterval time.Duration, updateInterval time.Duration) *OrchRewardsExporter {
+func NewOrchRewardsExporter(orchAddress string, fetchInterval time.Duration, updateInterval time.Duration, poolHistoryRounds int) *OrchRewardsExporter {
 	exporter := &OrchRewardsExporter{
-		orchAddress:             orchAddress,
-		fetchInterval:           fetchInterval,
-		updateInterval:          updateInterval,
-		orchRewardsEndpoint:     rewardEventsEndpoint,
-		orchRewardsGraphqlQuery: fmt.Sprintf(graphqlQueryTemplate, orchAddress),
-		orchRewards:             &rewardEventResponse{},
+		orchAddress:         orchAddress,
+		fetchInterval:       fetchInterval,
+		updateInterval:      updateInterval,
+		poolHistoryRounds:   poolHistoryRounds,
+		orchRewardsEndpoint: rewardEventsEndpoint,
+		orchRewards:         &rewardEventResponse{},
 	}
 
 	// Create request headers.
@@ -336,10 +531,9 @@ func NewOrchRewardsExporter(orchAddress string, fetchInterval time.Duration, upd
 		"X-Device-ID": {fmt.Sprintf(constants.ClientIDTemplate, orchAddress)},
 	}
 
-	// Initialize fetcher.
+	// Initialize fetcher. The target of each query is set when fetching.
 	exporter.orchRewardsFetcher = fetcher.Fetcher{
 		URL:     exporter.orchRewardsEndpoint,
-		Data:    &exporter.orchRewards,
 		Headers: headers,
 	}
 
@@ -350,11 +544,117 @@ func NewOrchRewardsExporter(orchAddress string, fetchInterval time.Duration, upd
 	return exporter
 }
 
+// Fetch fetches the current round and all of the orchestrator's pools and reward events, page by page,
+// from the Livepeer subgraph GraphQL API. The previously fetched data is only replaced when all queries
+// succeeded.
+func (m *OrchRewardsExporter) Fetch() error {
+	response := &rewardEventResponse{}
+	responseFetcher := m.orchRewardsFetcher
+	responseFetcher.Data = response
+	if err := responseFetcher.FetchGraphQLData(protocolQuery); err != nil {
+		return fmt.Errorf("error fetching current round: %w", err)
+	}
+
+	var pools []pool
+	for {
+		var cursor string
+		if len(pools) > 0 {
+			cursor = pools[len(pools)-1].ID
+		}
+		page := &rewardEventResponse{}
+		pageFetcher := m.orchRewardsFetcher
+		pageFetcher.Data = page
+		if err := pageFetcher.FetchGraphQLData(fmt.Sprintf(poolsQueryTemplate, m.orchAddress, cursor, constants.SubgraphPageSize)); err != nil {
+			return fmt.Errorf("error fetching pools after '%s': %w", cursor, err)
+		}
+		pools = append(pools, page.Data.Pools...)
+		if len(page.Data.Pools) < constants.SubgraphPageSize {
+			break
+		}
+	}
+
+	// Order the pools from the latest to the oldest round, since their IDs do not sort numerically.
+	sort.SliceStable(pools, func(i, j int) bool {
+		roundI, _ := strconv.Atoi(pools[i].Round.ID)
+		roundJ, _ := strconv.Atoi(pools[j].Round.ID)
+		return roundI > roundJ
+	})
+	response.Data.Pools = pools
+
+	var events []rewardEvent
+	for {
+		var cursor string
+		if len(events) > 0 {
+			cursor = events[len(events)-1].ID
+		}
+		page := &rewardEventResponse{}
+		pageFetcher := m.orchRewardsFetcher
+		pageFetcher.Data = page
+		if err := pageFetcher.FetchGraphQLData(fmt.Sprintf(rewardEventsQueryTemplate, m.orchAddress, cursor, constants.SubgraphPageSize)); err != nil {
+			return fmt.Errorf("error fetching reward events after '%s': %w", cursor, err)
+		}
+		events = append(events, page.Data.RewardEvents...)
+		if len(page.Data.RewardEvents) < constants.SubgraphPageSize {
+			break
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Transaction.Timestamp < events[j].Transaction.Timestamp
+	})
+	response.Data.RewardEvents = events
+
+	m.orchRewards.Mutex.Lock()
+	defer m.orchRewards.Mutex.Unlock()
+	m.orchRewards.Data = response.Data
+	m.orchRewards.Complete = true
+	return nil
+}
+
+// Complete reports whether all reward events of the orchestrator were fetched.
+func (m *OrchRewardsExporter) Complete() bool {
+	m.orchRewards.Mutex.Lock()
+	defer m.orchRewards.Mutex.Unlock()
+	return m.orchRewards.Complete
+}
+
+// Rewards returns the orchestrator's rewards parsed from the latest fetched data.
+func (m *OrchRewardsExporter) Rewards() []Reward {
+	m.orchRewards.Mutex.Lock()
+	defer m.orchRewards.Mutex.Unlock()
+
+	rewards := make([]Reward, 0, len(m.orchRewards.Data.RewardEvents))
+	for _, event := range m.orchRewards.Data.RewardEvents {
+		rewards = append(rewards, parseReward(event))
+	}
+	return rewards
+}
+
+// MintRate returns the amount of LPT minted per staked LPT in the latest round of the orchestrator's
+// pools. It returns 0 if no round data is available.
+func (m *OrchRewardsExporter) MintRate() float64 {
+	m.orchRewards.Mutex.Lock()
+	defer m.orchRewards.Mutex.Unlock()
+
+	// The pools are ordered from the latest to the oldest round.
+	for _, pool := range m.orchRewards.Data.Pools {
+		var inflation, totalSupply, totalActiveStake float64
+		util.SetFloatFromStr(&inflation, pool.Round.Inflation)
+		util.SetFloatFromStr(&totalSupply, pool.Round.TotalSupply)
+		util.SetFloatFromStr(&totalActiveStake, pool.Round.TotalActiveStake)
+		if totalActiveStake > 0 {
+			return totalSupply * inflation / inflationDivisor / totalActiveStake
+		}
+	}
+	return 0
+}
+
 // Start starts the OrchRewardsExporter.
 func (m *OrchRewardsExporter) Start() {
 	// Fetch initial data and update metrics.
-	m.orchRewardsFetcher.FetchGraphQLData(m.orchRewardsGraphqlQuery)
+	m.Fetch()
+	m.orchRewards.Mutex.Lock()
 	m.updateMetrics()
+	m.orchRewards.Mutex.Unlock()
 
 	// Start fetcher in a goroutine.
 	go func() {
@@ -362,9 +662,7 @@ func (m *OrchRewardsExporter) Start() {
 		defer ticker.Stop()
 
 		for range ticker.C {
-			m.orchRewards.Mutex.Lock()
-			m.orchRewardsFetcher.FetchGraphQLData(m.orchRewardsGraphqlQuery)
-			m.orchRewards.Mutex.Unlock()
+			m.Fetch()
 		}
 	}()
 
@@ -16,8 +16,8 @@ var (
 	orchScoreEndpointTemplate = "https://explorer.livepeer.org/api/score/%s"
 )
 
-// orchScore represents the structure of the data returned by the Livepeer orchestrator score API.
-type orchScore struct {
+// OrchScore represents the structure of the data returned by the Livepeer orchestrator score API.
+type OrchScore struct {
 	Mutex sync.Mutex
 
 	// Response data.
@@ -41,7 +41,7 @@ type OrchScoreExporter struct {
 	orchInfoEndpoint string        // The endpoint to fetch data from.
 
 	// Data.
-	orchScore *orchScore // The data returned by the API.
+	orchScore *OrchScore // The data returned by the API.
 
 	// Fetchers.
 	orchScoreFetcher fetcher.Fetcher
@@ -109,13 +109,18 @@ func (m *OrchScoreExporter) updateMetrics() {
 	}
 }
 
+// GetOrchScoreEndpoint returns the Livepeer orchestrator score API endpoint of the given orchestrator.
+func GetOrchScoreEndpoint(orchAddress string) string {
+	return fmt.Sprintf(orchScoreEndpointTemplate, orchAddress)
+}
+
 // NewOrchScoreExporter creates a new OrchScoreExporter.
 func NewOrchScoreExporter(orchAddress string, fetchInterval time.Duration, updateInterval time.Duration) *OrchScoreExporter {
 	exporter := &OrchScoreExporter{
 		fetchInterval:    fetchInterval,
 		updateInterval:   updateInterval,
-		orchInfoEndpoint: fmt.Sprintf(orchScoreEndpointTemplate, orchAddress),
-		orchScore:        &orchScore{},
+		orchInfoEndpoint: GetOrchScoreEndpoint(orchAddress),
+		orchScore:        &OrchScore{},
 	}
 
 	// Create request headers.
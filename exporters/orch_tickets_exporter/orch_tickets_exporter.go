@@ -7,6 +7,9 @@ import (
 	"fmt"
 	"livepeer-exporter/constants"
 	"livepeer-exporter/fetcher"
+	"livepeer-exporter/receipts"
+	"livepeer-exporter/util"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
@@ -18,10 +21,71 @@ var (
 	winningTicketRedeemedEventsEndpoint = constants.LivePeerSubgraphEndpoint
 )
 
-// graphqlQuery represents the GraphQL query to fetch data from the GraphQL API.
+// faceValueBuckets are the upper bounds of the winning ticket face value histogram buckets in ETH.
+var faceValueBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1}
+
+// faceValueHistogram is a Prometheus collector that exposes the face value distribution of the winning
+// tickets as a histogram. Since the tickets are refetched, the histogram is rebuilt on each update
+// instead of observing every ticket once.
+type faceValueHistogram struct {
+	sync.Mutex
+
+	desc      *prometheus.Desc
+	histogram prometheus.Metric // The latest histogram. Nil until the first update.
+}
+
+// newFaceValueHistogram creates a new faceValueHistogram.
+func newFaceValueHistogram() *faceValueHistogram {
+	return &faceValueHistogram{
+		desc: prometheus.NewDesc(
+			"livepeer_orch_winning_ticket_face_value",
+			"The distribution of the face values of the winning tickets in ETH.",
+			nil, nil,
+		),
+	}
+}
+
+// update rebuilds the histogram from the face values of the given tickets.
+func (h *faceValueHistogram) update(tickets []WinningTicket) {
+	buckets := make(map[float64]uint64, len(faceValueBuckets))
+	var sum float64
+	for _, ticket := range tickets {
+		sum += ticket.FaceValue
+		for _, bucket := range faceValueBuckets {
+			if ticket.FaceValue <= bucket {
+				buckets[bucket]++
+			}
+		}
+	}
+
+	h.Lock()
+	defer h.Unlock()
+	h.histogram = prometheus.MustNewConstHistogram(h.desc, uint64(len(tickets)), sum, buckets)
+}
+
+// Describe implements prometheus.Collector.
+func (h *faceValueHistogram) Describe(ch chan<- *prometheus.Desc) {
+	ch <- h.desc
+}
+
+// Collect implements prometheus.Collector.
+func (h *faceValueHistogram) Collect(ch chan<- prometheus.Metric) {
+	h.Lock()
+	defer h.Unlock()
+	if h.histogram != nil {
+		ch <- h.histogram
+	}
+}
+
+// otherSendersLabel is the sender label of the aggregated tickets of the senders outside the top senders.
+const otherSendersLabel = "other"
+
+// graphqlQuery represents the GraphQL query to fetch a page of data from the GraphQL API. The events
+// are paginated by ID since the subgraph only returns a limited number of events per query.
 const graphqlQueryTemplate = `
 {
-	winningTicketRedeemedEvents(where: {recipient: "%s"}) {
+	winningTicketRedeemedEvents(where: {recipient: "%s", id_gt: "%s"}, orderBy: id, orderDirection: asc, first: %d) {
+		id
 		transaction {
 			gasUsed
 			gasPrice
@@ -32,6 +96,9 @@ const graphqlQueryTemplate = `
 		round {
 			id
 		}
+		sender {
+			id
+		}
 		faceValue
 	}
 }
@@ -39,6 +106,7 @@ const graphqlQueryTemplate = `
 
 // winningTicketRedeemedEvent represents the structure of the winningTicketRedeemedEvent field contained in the GraphQL API response.
 type winningTicketRedeemedEvent struct {
+	ID          string
 	Transaction struct {
 		GasUsed     string
 		GasPrice    string
@@ -49,6 +117,9 @@ type winningTicketRedeemedEvent struct {
 	Round struct {
 		ID string
 	}
+	Sender struct {
+		ID string
+	}
 	FaceValue string
 }
 
@@ -60,6 +131,97 @@ type winningTicketRedeemedResponse struct {
 	Data struct {
 		WinningTicketRedeemedEvents []winningTicketRedeemedEvent
 	}
+
+	// Whether all events were fetched.
+	Complete bool
+}
+
+// WinningTicket represents a parsed winning ticket redeemed event.
+type WinningTicket struct {
+	ID          string    // The transaction hash of the ticket redemption.
+	Timestamp   time.Time // The block time of the ticket redemption.
+	BlockNumber float64   // The block number of the ticket redemption.
+	Round       float64   // The round in which the ticket was redeemed.
+	Sender      string    // The address of the broadcaster that sent the ticket.
+	FaceValue   float64   // The face value of the ticket in ETH.
+	GasUsed     float64   // The gas used by the ticket redemption.
+	GasPrice    float64   // The gas price of the ticket redemption in Wei.
+	GasCost     float64   // The gas cost of the ticket redemption in ETH.
+}
+
+// parseWinningTicket parses a winningTicketRedeemedEvent into a WinningTicket.
+func parseWinningTicket(event winningTicketRedeemedEvent) WinningTicket {
+	ticket := WinningTicket{
+		ID:        event.Transaction.ID,
+		Timestamp: time.Unix(int64(event.Transaction.Timestamp), 0),
+		Sender:    event.Sender.ID,
+	}
+	ticket.FaceValue, _ = strconv.ParseFloat(event.FaceValue, 64)
+	ticket.GasUsed, _ = strconv.ParseFloat(event.Transaction.GasUsed, 64)
+	ticket.GasPrice, _ = strconv.ParseFloat(event.Transaction.GasPrice, 64)
+	ticket.GasCost = (ticket.GasUsed * ticket.GasPrice) / 1e18
+	ticket.BlockNumber, _ = strconv.ParseFloat(event.Transaction.BlockNumber, 64)
+	ticket.Round, _ = strconv.ParseFloat(event.Round.ID, 64)
+	return ticket
+}
+
+// senderTickets represents the aggregated winning tickets of a single sender.
+type senderTickets struct {
+	Fees           map[string]float64 // The ETH fees won per period.
+	TicketCount    map[string]float64 // The number of winning tickets per period.
+	LastTicketTime time.Time          // The block time of the last winning ticket.
+}
+
+// getSenderTickets aggregates the winning tickets per sender over the given periods. The senders that
+// did not send one of the given number of highest total fees are aggregated under the 'other' sender.
+func getSenderTickets(tickets []WinningTicket, periods []util.Period, topN int) map[string]*senderTickets {
+	// Determine the senders with the highest total fees.
+	totalFees := make(map[string]float64)
+	for _, ticket := range tickets {
+		totalFees[ticket.Sender] += ticket.FaceValue
+	}
+	senders := make([]string, 0, len(totalFees))
+	for sender := range totalFees {
+		senders = append(senders, sender)
+	}
+	sort.Slice(senders, func(i, j int) bool {
+		return totalFees[senders[i]] > totalFees[senders[j]]
+	})
+	topSenders := make(map[string]bool)
+	for i := 0; i < len(senders) && i < topN; i++ {
+		topSenders[senders[i]] = true
+	}
+
+	// Aggregate the tickets per sender.
+	senderTicketsMap := make(map[string]*senderTickets)
+	for _, ticket := range tickets {
+		sender := ticket.Sender
+		if !topSenders[sender] {
+			sender = otherSendersLabel
+		}
+		if senderTicketsMap[sender] == nil {
+			senderTicketsMap[sender] = &senderTickets{Fees: make(map[string]float64), TicketCount: make(map[string]float64)}
+		}
+		aggregate := senderTicketsMap[sender]
+		for _, period := range periods {
+			if !ticket.Timestamp.Before(period.Start) {
+				aggregate.Fees[period.Name] += ticket.FaceValue
+				aggregate.TicketCount[period.Name]++
+			}
+		}
+		if ticket.Timestamp.After(aggregate.LastTicketTime) {
+			aggregate.LastTicketTime = ticket.Timestamp
+		}
+	}
+	return senderTicketsMap
+}
+
+// getGasCostRatio returns the given gas cost of the ticket redemption as a fraction of the ticket's face value.
+func getGasCostRatio(ticket WinningTicket, gasCost float64) float64 {
+	if ticket.FaceValue == 0 {
+		return 0
+	}
+	return gasCost / ticket.FaceValue
 }
 
 // OrchTicketsExporter fetches data from the API and exposes orchestrator's tickets metrics via Prometheus.
@@ -85,18 +247,33 @@ type OrchTicketsExporter struct {
 	YearGasCost              prometheus.Gauge
 	TotalGasCost             prometheus.Gauge
 
+	// Sender metrics.
+	SenderFees           *prometheus.GaugeVec
+	SenderTicketCount    *prometheus.GaugeVec
+	SenderLastTicketTime *prometheus.GaugeVec
+
+	// Redemption economics metrics.
+	WinningTicketGasCostRatio *prometheus.GaugeVec
+	FaceValue                 *faceValueHistogram
+	GasCostRatio              *prometheus.GaugeVec
+	CostlyRedemptionCount     *prometheus.GaugeVec
+
 	// Config settings.
-	orchAddress             string        // The orchestrator address to filter tickets by.
-	fetchInterval           time.Duration // How often to fetch data.
-	updateInterval          time.Duration // How often to update metrics.
-	orchTicketsEndpoint     string        // The endpoint to fetch data from.
-	orchTicketsGraphqlQuery string        // The GraphQL query to fetch data from the GraphQL API.
+	orchAddress           string        // The orchestrator address to filter tickets by.
+	fetchInterval         time.Duration // How often to fetch data.
+	updateInterval        time.Duration // How often to update metrics.
+	orchTicketsEndpoint   string        // The endpoint to fetch data from.
+	sendersTopN           int           // The number of senders with the highest fees to expose.
+	costlyRedemptionRatio float64       // The gas cost to face value ratio above which a redemption is costly.
 
 	// Data.
 	orchTickets *winningTicketRedeemedResponse // The data returned by the API.
 
 	// Fetchers.
 	orchTicketsFetcher fetcher.Fetcher
+
+	// Data sources.
+	receiptProvider *receipts.ReceiptProvider // Provides the receipts of the ticket redemptions.
 }
 
 // initMetrics initializes the orchestrator tickets metrics.
@@ -222,6 +399,49 @@ func (m *OrchTicketsExporter) initMetrics() {
 			Help: "The total gas cost for all ticket redeem transactions.",
 		},
 	)
+	m.SenderFees = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_sender_fees",
+			Help: "The amount of ETH fees won from the tickets of each sender per period.",
+		},
+		[]string{"sender", "period"},
+	)
+	m.SenderTicketCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_sender_winning_ticket_count",
+			Help: "The number of winning tickets of each sender per period.",
+		},
+		[]string{"sender", "period"},
+	)
+	m.SenderLastTicketTime = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_sender_last_winning_ticket_time",
+			Help: "The block time of the last winning ticket of each sender.",
+		},
+		[]string{"sender"},
+	)
+	m.WinningTicketGasCostRatio = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_winning_ticket_gas_cost_ratio",
+			Help: "The gas cost of each ticket redemption as a fraction of the ticket's face value.",
+		},
+		[]string{"id"},
+	)
+	m.FaceValue = newFaceValueHistogram()
+	m.GasCostRatio = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_tickets_gas_cost_ratio",
+			Help: "The gas cost of all ticket redemptions as a fraction of the won face value per period.",
+		},
+		[]string{"period"},
+	)
+	m.CostlyRedemptionCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_costly_ticket_redemption_count",
+			Help: "The number of ticket redemptions of which the gas cost exceeded the configured fraction of the face value per period.",
+		},
+		[]string{"period"},
+	)
 }
 
 // registerMetrics registers the orchestrator tickets metrics with Prometheus.
@@ -246,6 +466,13 @@ func (m *OrchTicketsExporter) registerMetrics() {
 		m.NinetyDayGasCost,
 		m.YearGasCost,
 		m.TotalGasCost,
+		m.SenderFees,
+		m.SenderTicketCount,
+		m.SenderLastTicketTime,
+		m.WinningTicketGasCostRatio,
+		m.FaceValue,
+		m.GasCostRatio,
+		m.CostlyRedemptionCount,
 	)
 }
 
@@ -260,25 +487,27 @@ func (m *OrchTicketsExporter) updateMetrics() {
 	yearAgo := now.AddDate(-1, 0, 0)
 
 	// Set the metrics for each ticket.
+	tickets := make([]WinningTicket, 0, len(m.orchTickets.Data.WinningTicketRedeemedEvents))
 	var totalFees, totalGasCost float64
 	var dayFees, weekFees, thirtyDayFees, ninetyDayFees, yearFees float64
 	var dayGasCost, weekGasCost, thirtyDayGasCost, ninetyDayGasCost, yearGasCost float64
-	for _, ticket := range m.orchTickets.Data.WinningTicketRedeemedEvents {
-		amount, _ := strconv.ParseFloat(ticket.FaceValue, 64)
-		gasUsed, _ := strconv.ParseFloat(ticket.Transaction.GasUsed, 64)
-		gasPrice, _ := strconv.ParseFloat(ticket.Transaction.GasPrice, 64)
-		gasCost := (gasUsed * gasPrice) / 1e9
-		blockNumber, _ := strconv.ParseFloat(ticket.Transaction.BlockNumber, 64)
-		blockTime, _ := strconv.ParseFloat(strconv.Itoa(ticket.Transaction.Timestamp), 64)
-		round, _ := strconv.ParseFloat(ticket.Round.ID, 64)
-
-		m.WinningTicketAmount.WithLabelValues(ticket.Transaction.ID).Set(amount)
-		m.WinningTicketGasUsed.WithLabelValues(ticket.Transaction.ID).Set(gasUsed)
-		m.WinningTicketGasPrice.WithLabelValues(ticket.Transaction.ID).Set(gasPrice)
-		m.WinningTicketGasCost.WithLabelValues(ticket.Transaction.ID).Set(gasCost)
-		m.WinningTicketBlockNumber.WithLabelValues(ticket.Transaction.ID).Set(blockNumber)
-		m.WinningTicketBlockTime.WithLabelValues(ticket.Transaction.ID).Set(blockTime * 1000) // Grafana expects milliseconds.
-		m.WinningTicketRound.WithLabelValues(ticket.Transaction.ID).Set(round)
+	for _, event := range m.orchTickets.Data.WinningTicketRedeemedEvents {
+		ticket := parseWinningTicket(event)
+		tickets = append(tickets, ticket)
+		amount := ticket.FaceValue
+		gasCost := ticket.GasCost * 1e9 // Expressed in Gwei.
+		blockTime := float64(ticket.Timestamp.Unix())
+
+		m.WinningTicketAmount.WithLabelValues(ticket.ID).Set(amount)
+		m.WinningTicketGasUsed.WithLabelValues(ticket.ID).Set(ticket.GasUsed)
+		m.WinningTicketGasPrice.WithLabelValues(ticket.ID).Set(ticket.GasPrice)
+		m.WinningTicketGasCost.WithLabelValues(ticket.ID).Set(gasCost)
+		m.WinningTicketBlockNumber.WithLabelValues(ticket.ID).Set(ticket.BlockNumber)
+		m.WinningTicketBlockTime.WithLabelValues(ticket.ID).Set(blockTime * 1000) // Grafana expects milliseconds.
+		m.WinningTicketRound.WithLabelValues(ticket.ID).Set(ticket.Round)
+		if receipt, ok := m.receiptProvider.GetReceipt(ticket.ID); ok {
+			m.WinningTicketGasCostRatio.WithLabelValues(ticket.ID).Set(getGasCostRatio(ticket, receipt.GasCost()))
+		}
 
 		// Calculate the fees and gas costs for different periods.
 		if blockTime >= float64(dayAgo.Unix()) {
@@ -318,17 +547,78 @@ func (m *OrchTicketsExporter) updateMetrics() {
 	m.NinetyDayGasCost.Set(ninetyDayGasCost)
 	m.YearGasCost.Set(yearGasCost)
 	m.TotalGasCost.Set(totalGasCost)
+
+	// Set the sender and redemption economics metrics.
+	periods := util.GetPeriods(now)
+	m.updateSenderMetrics(tickets, periods)
+	m.updateEconomicsMetrics(tickets, periods)
+}
+
+// updateSenderMetrics updates the fees, ticket counts and last ticket time of the senders with the
+// highest fees.
+func (m *OrchTicketsExporter) updateSenderMetrics(tickets []WinningTicket, periods []util.Period) {
+	m.SenderFees.Reset()
+	m.SenderTicketCount.Reset()
+	m.SenderLastTicketTime.Reset()
+	for sender, aggregate := range getSenderTickets(tickets, periods, m.sendersTopN) {
+		for _, period := range periods {
+			m.SenderFees.WithLabelValues(sender, period.Name).Set(aggregate.Fees[period.Name])
+			m.SenderTicketCount.WithLabelValues(sender, period.Name).Set(aggregate.TicketCount[period.Name])
+		}
+		m.SenderLastTicketTime.WithLabelValues(sender).Set(float64(aggregate.LastTicketTime.Unix()) * 1000) // Grafana expects milliseconds.
+	}
+}
+
+// updateEconomicsMetrics updates the face value distribution of the winning tickets and the gas cost of
+// the ticket redemptions relative to the won face value. The gas costs are taken from the transaction
+// receipts, since the gas used reported by the subgraph holds the gas limit, and are only used once the
+// receipts of all ticket redemptions were fetched.
+func (m *OrchTicketsExporter) updateEconomicsMetrics(tickets []WinningTicket, periods []util.Period) {
+	// Rebuild the face value histogram.
+	m.FaceValue.update(tickets)
+
+	ids := make([]string, 0, len(tickets))
+	for _, ticket := range tickets {
+		ids = append(ids, ticket.ID)
+	}
+	gasCosts, ok := m.receiptProvider.GetGasCosts(ids)
+	if !ok {
+		return
+	}
+
+	// Calculate the gas cost ratio and the number of costly redemptions per period.
+	for _, period := range periods {
+		var faceValue, gasCost, costlyRedemptions float64
+		for _, ticket := range tickets {
+			if ticket.Timestamp.Before(period.Start) {
+				continue
+			}
+			faceValue += ticket.FaceValue
+			gasCost += gasCosts[ticket.ID]
+			if gasCosts[ticket.ID] > ticket.FaceValue*m.costlyRedemptionRatio {
+				costlyRedemptions++
+			}
+		}
+		var gasCostRatio float64
+		if faceValue > 0 {
+			gasCostRatio = gasCost / faceValue
+		}
+		m.GasCostRatio.WithLabelValues(period.Name).Set(gasCostRatio)
+		m.CostlyRedemptionCount.WithLabelValues(period.Name).Set(costlyRedemptions)
+	}
 }
 
 // NewOrchTicketsExporter creates a new OrchTicketsExporter.
-func NewOrchTicketsExporter(orchAddress string, fetchInterval time.Duration, updateInterval time.Duration) *OrchTicketsExporter {
+func NewOrchTicketsExporter(orchAddress string, fetchInterval time.Duration, updateInterval time.Duration, sendersTopN int, costlyRedemptionRatio float64, receiptProvider *receipts.ReceiptProvider) *OrchTicketsExporter {
 	exporter := &OrchTicketsExporter{
-		orchAddress:             orchAddress,
-		fetchInterval:           fetchInterval,
-		updateInterval:          updateInterval,
-		orchTicketsEndpoint:     winningTicketRedeemedEventsEndpoint,
-		orchTicketsGraphqlQuery: fmt.Sprintf(graphqlQueryTemplate, orchAddress),
-		orchTickets:             &winningTicketRedeemedResponse{},
+		orchAddress:           orchAddress,
+		fetchInterval:         fetchInterval,
+		updateInterval:        updateInterval,
+		orchTicketsEndpoint:   winningTicketRedeemedEventsEndpoint,
+		orchTickets:           &winningTicketRedeemedResponse{},
+		sendersTopN:           sendersTopN,
+		costlyRedemptionRatio: costlyRedemptionRatio,
+		receiptProvider:       receiptProvider,
 	}
 
 	// Create request headers.
@@ -336,10 +626,9 @@ func NewOrchTicketsExporter(orchAddress string, fetchInterval time.Duration, upd
 		"X-Device-ID": {fmt.Sprintf(constants.ClientIDTemplate, orchAddress)},
 	}
 
-	// Initialize fetcher.
+	// Initialize fetcher. The target of each page is set when fetching.
 	exporter.orchTicketsFetcher = fetcher.Fetcher{
 		URL:     exporter.orchTicketsEndpoint,
-		Data:    &exporter.orchTickets,
 		Headers: headers,
 	}
 
@@ -350,11 +639,74 @@ func NewOrchTicketsExporter(orchAddress string, fetchInterval time.Duration, upd
 	return exporter
 }
 
+// Fetch fetches all winning tickets of the orchestrator from the Livepeer subgraph GraphQL API page by
+// page. The previously fetched tickets are only replaced when all pages were fetched successfully.
+func (m *OrchTicketsExporter) Fetch() error {
+	var events []winningTicketRedeemedEvent
+	for {
+		var cursor string
+		if len(events) > 0 {
+			cursor = events[len(events)-1].ID
+		}
+		page := &winningTicketRedeemedResponse{}
+		pageFetcher := m.orchTicketsFetcher
+		pageFetcher.Data = page
+		if err := pageFetcher.FetchGraphQLData(fmt.Sprintf(graphqlQueryTemplate, m.orchAddress, cursor, constants.SubgraphPageSize)); err != nil {
+			return fmt.Errorf("error fetching winning tickets after '%s': %w", cursor, err)
+		}
+		events = append(events, page.Data.WinningTicketRedeemedEvents...)
+		if len(page.Data.WinningTicketRedeemedEvents) < constants.SubgraphPageSize {
+			break
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Transaction.Timestamp < events[j].Transaction.Timestamp
+	})
+
+	m.orchTickets.Mutex.Lock()
+	defer m.orchTickets.Mutex.Unlock()
+	m.orchTickets.Data.WinningTicketRedeemedEvents = events
+	m.orchTickets.Complete = true
+	return nil
+}
+
+// fetchReceipts fetches the receipts of the ticket redemptions that were not fetched before.
+func (m *OrchTicketsExporter) fetchReceipts() {
+	tickets := m.Tickets()
+	ids := make([]string, 0, len(tickets))
+	for _, ticket := range tickets {
+		ids = append(ids, ticket.ID)
+	}
+	m.receiptProvider.Fetch(ids)
+}
+
+// Complete reports whether all winning tickets of the orchestrator were fetched.
+func (m *OrchTicketsExporter) Complete() bool {
+	m.orchTickets.Mutex.Lock()
+	defer m.orchTickets.Mutex.Unlock()
+	return m.orchTickets.Complete
+}
+
+// Tickets returns the orchestrator's winning tickets parsed from the latest fetched data.
+func (m *OrchTicketsExporter) Tickets() []WinningTicket {
+	m.orchTickets.Mutex.Lock()
+	defer m.orchTickets.Mutex.Unlock()
+
+	tickets := make([]WinningTicket, 0, len(m.orchTickets.Data.WinningTicketRedeemedEvents))
+	for _, event := range m.orchTickets.Data.WinningTicketRedeemedEvents {
+		tickets = append(tickets, parseWinningTicket(event))
+	}
+	return tickets
+}
+
 // Start starts the OrchTicketsExporter.
 func (m *OrchTicketsExporter) Start() {
 	// Fetch initial data and update metrics.
-	m.orchTicketsFetcher.FetchGraphQLData(m.orchTicketsGraphqlQuery)
+	m.Fetch()
+	m.fetchReceipts()
+	m.orchTickets.Mutex.Lock()
 	m.updateMetrics()
+	m.orchTickets.Mutex.Unlock()
 
 	// Start fetcher in a goroutine.
 	go func() {
@@ -362,9 +714,8 @@ func (m *OrchTicketsExporter) Start() {
 		defer ticker.Stop()
 
 		for range ticker.C {
-			m.orchTickets.Mutex.Lock()
-			m.orchTicketsFetcher.FetchGraphQLData(m.orchTicketsGraphqlQuery)
-			m.orchTickets.Mutex.Unlock()
+			m.Fetch()
+			m.fetchReceipts()
 		}
 	}()
 
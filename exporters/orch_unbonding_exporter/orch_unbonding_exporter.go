@@ -0,0 +1,270 @@
+// Package orch_unbonding_exporter implements a Livepeer orchestrator unbonding exporter that fetches the
+// unbonding locks of the orchestrator's delegators from the Livepeer subgraph GraphQL API endpoint and
+// exposes the LPT that is unbonding from the orchestrator via Prometheus metrics.
+package orch_unbonding_exporter
+
+import (
+	"fmt"
+	"livepeer-exporter/constants"
+	"livepeer-exporter/fetcher"
+	"livepeer-exporter/util"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	unbondingLocksEndpoint = constants.LivePeerSubgraphEndpoint
+)
+
+// graphqlQueryTemplate represents the GraphQL query to fetch a page of unbonding locks from the GraphQL API.
+// The locks are ordered by ID so that the last ID of a page can be used as the cursor of the next page.
+const graphqlQueryTemplate = `
+{
+	unbondingLocks(where: {delegate: "%s", id_gt: "%s"}, orderBy: id, orderDirection: asc, first: %d) {
+		id
+		amount
+		withdrawRound
+		delegator {
+			id
+		}
+	}
+	protocol(id: "0") {
+		currentRound {
+			id
+		}
+	}
+}
+`
+
+// unbondingLock represents the structure of the unbondingLocks field contained in the GraphQL API response.
+type unbondingLock struct {
+	ID            string
+	Amount        string
+	WithdrawRound string
+	Delegator     struct {
+		ID string
+	}
+}
+
+// unbondingLocksResponse represents the structure of the GraphQL API response.
+type unbondingLocksResponse struct {
+	sync.Mutex
+
+	// Response data.
+	Data struct {
+		UnbondingLocks []unbondingLock
+		Protocol       struct {
+			CurrentRound struct {
+				ID string
+			}
+		}
+	}
+
+	// Whether all unbonding locks were fetched. The previous locks are kept when a fetch fails.
+	Complete bool
+}
+
+// OrchUnbondingExporter fetches data from the API and exposes the LPT unbonding from the orchestrator via Prometheus.
+type OrchUnbondingExporter struct {
+	// Metrics.
+	UnbondingAmount        prometheus.Gauge
+	UnbondingAmountByRound *prometheus.GaugeVec
+	SelfUnbondingAmount    prometheus.Gauge
+	WithdrawableAmount     prometheus.Gauge
+	UnbondingLockCount     prometheus.Gauge
+
+	// Config settings.
+	orchAddress           string        // The orchestrator address.
+	orchAddressSecondary  string        // The secondary orchestrator address.
+	fetchInterval         time.Duration // How often to fetch data.
+	updateInterval        time.Duration // How often to update metrics.
+	orchUnbondingEndpoint string        // The endpoint to fetch data from.
+
+	// Data.
+	orchUnbondingLocks *unbondingLocksResponse // The data returned by the API.
+
+	// Fetchers.
+	orchUnbondingFetcher fetcher.Fetcher
+}
+
+// initMetrics initializes the orchestrator unbonding metrics.
+func (m *OrchUnbondingExporter) initMetrics() {
+	m.UnbondingAmount = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_unbonding_amount",
+			Help: "The total amount of LPT that is currently unbonding from the orchestrator.",
+		},
+	)
+	m.UnbondingAmountByRound = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_unbonding_amount_by_withdraw_round",
+			Help: "The amount of LPT that is unbonding from the orchestrator per withdraw round.",
+		},
+		[]string{"withdraw_round"},
+	)
+	m.SelfUnbondingAmount = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_self_unbonding_amount",
+			Help: "The amount of LPT that the orchestrator itself is currently unbonding.",
+		},
+	)
+	m.WithdrawableAmount = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_unbonded_withdrawable_amount",
+			Help: "The amount of unbonded LPT that can be withdrawn but has not been withdrawn yet.",
+		},
+	)
+	m.UnbondingLockCount = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_unbonding_lock_count",
+			Help: "The number of unbonding locks that are currently unbonding from the orchestrator.",
+		},
+	)
+}
+
+// registerMetrics registers the orchestrator unbonding metrics with Prometheus.
+func (m *OrchUnbondingExporter) registerMetrics() {
+	prometheus.MustRegister(
+		m.UnbondingAmount,
+		m.UnbondingAmountByRound,
+		m.SelfUnbondingAmount,
+		m.WithdrawableAmount,
+		m.UnbondingLockCount,
+	)
+}
+
+// updateMetrics updates the metrics with the data fetched from the Livepeer subgraph GraphQL API.
+func (m *OrchUnbondingExporter) updateMetrics() {
+	// Wait until all unbonding locks were fetched so that the totals are not underestimated.
+	if !m.orchUnbondingLocks.Complete {
+		return
+	}
+
+	var currentRound float64
+	util.SetFloatFromStr(&currentRound, m.orchUnbondingLocks.Data.Protocol.CurrentRound.ID)
+
+	// Aggregate the unbonding locks. Locks of which the withdraw round has passed no longer unbond
+	// but still hold LPT that has not been withdrawn.
+	var unbondingAmount, selfUnbondingAmount, withdrawableAmount, unbondingLockCount float64
+	amountByRound := make(map[float64]float64)
+	for _, lock := range m.orchUnbondingLocks.Data.UnbondingLocks {
+		var amount, withdrawRound float64
+		util.SetFloatFromStr(&amount, lock.Amount)
+		util.SetFloatFromStr(&withdrawRound, lock.WithdrawRound)
+
+		if withdrawRound <= currentRound {
+			withdrawableAmount += amount
+			continue
+		}
+		unbondingAmount += amount
+		unbondingLockCount++
+		amountByRound[withdrawRound] += amount
+		if lock.Delegator.ID == m.orchAddress || (m.orchAddressSecondary != "" && lock.Delegator.ID == m.orchAddressSecondary) {
+			selfUnbondingAmount += amount
+		}
+	}
+
+	// Set the metrics.
+	m.UnbondingAmount.Set(unbondingAmount)
+	m.SelfUnbondingAmount.Set(selfUnbondingAmount)
+	m.WithdrawableAmount.Set(withdrawableAmount)
+	m.UnbondingLockCount.Set(unbondingLockCount)
+	m.UnbondingAmountByRound.Reset()
+	for withdrawRound, amount := range amountByRound {
+		m.UnbondingAmountByRound.WithLabelValues(strconv.FormatFloat(withdrawRound, 'f', -1, 64)).Set(amount)
+	}
+}
+
+// fetchData fetches all unbonding locks of the orchestrator page by page. The locks are only replaced
+// when all pages were fetched.
+func (m *OrchUnbondingExporter) fetchData() {
+	var locks []unbondingLock
+	var page *unbondingLocksResponse
+	for {
+		var cursor string
+		if len(locks) > 0 {
+			cursor = locks[len(locks)-1].ID
+		}
+		page = &unbondingLocksResponse{}
+		pageFetcher := m.orchUnbondingFetcher
+		pageFetcher.Data = page
+		if err := pageFetcher.FetchGraphQLData(fmt.Sprintf(graphqlQueryTemplate, m.orchAddress, cursor, constants.SubgraphPageSize)); err != nil {
+			log.Printf("Error fetching unbonding locks after '%s': %v", cursor, err)
+			return
+		}
+		locks = append(locks, page.Data.UnbondingLocks...)
+		if len(page.Data.UnbondingLocks) < constants.SubgraphPageSize {
+			break
+		}
+	}
+
+	m.orchUnbondingLocks.Mutex.Lock()
+	m.orchUnbondingLocks.Data.UnbondingLocks = locks
+	m.orchUnbondingLocks.Data.Protocol = page.Data.Protocol
+	m.orchUnbondingLocks.Complete = true
+	m.orchUnbondingLocks.Mutex.Unlock()
+}
+
+// NewOrchUnbondingExporter creates a new OrchUnbondingExporter.
+func NewOrchUnbondingExporter(orchAddress string, fetchInterval time.Duration, updateInterval time.Duration, orchAddrSecondary string) *OrchUnbondingExporter {
+	exporter := &OrchUnbondingExporter{
+		orchAddress:           orchAddress,
+		orchAddressSecondary:  orchAddrSecondary,
+		fetchInterval:         fetchInterval,
+		updateInterval:        updateInterval,
+		orchUnbondingEndpoint: unbondingLocksEndpoint,
+		orchUnbondingLocks:    &unbondingLocksResponse{},
+	}
+
+	// Create request headers.
+	headers := map[string][]string{
+		"X-Device-ID": {fmt.Sprintf(constants.ClientIDTemplate, orchAddress)},
+	}
+
+	// Initialize fetcher. The target of each page is set when fetching.
+	exporter.orchUnbondingFetcher = fetcher.Fetcher{
+		URL:     exporter.orchUnbondingEndpoint,
+		Headers: headers,
+	}
+
+	// Initialize metrics.
+	exporter.initMetrics()
+	exporter.registerMetrics()
+
+	return exporter
+}
+
+// Start starts the OrchUnbondingExporter.
+func (m *OrchUnbondingExporter) Start() {
+	// Fetch initial data and update metrics.
+	m.fetchData()
+	m.orchUnbondingLocks.Mutex.Lock()
+	m.updateMetrics()
+	m.orchUnbondingLocks.Mutex.Unlock()
+
+	// Start fetcher in a goroutine.
+	go func() {
+		ticker := time.NewTicker(m.fetchInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			m.fetchData()
+		}
+	}()
+
+	// Start metrics updater in a goroutine.
+	go func() {
+		ticker := time.NewTicker(m.updateInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			m.orchUnbondingLocks.Mutex.Lock()
+			m.updateMetrics()
+			m.orchUnbondingLocks.Mutex.Unlock()
+		}
+	}()
+}
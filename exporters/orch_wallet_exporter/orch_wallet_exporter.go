@@ -0,0 +1,255 @@
+// Package orch_wallet_exporter implements a Livepeer orchestrator wallet exporter that fetches the ETH
+// balances of the orchestrator's wallets from an Arbitrum JSON-RPC endpoint and exposes them, together
+// with the gas runway estimated from the receipts of the transactions of the orch_tickets_exporter and
+// orch_rewards_exporter, via Prometheus metrics.
+package orch_wallet_exporter
+
+import (
+	"fmt"
+	"livepeer-exporter/exporters/orch_rewards_exporter"
+	"livepeer-exporter/exporters/orch_tickets_exporter"
+	"livepeer-exporter/fetcher"
+	"livepeer-exporter/receipts"
+	"livepeer-exporter/util"
+	"log"
+	"math"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// gasLookbackDays is the number of days over which the average daily gas cost is calculated.
+const gasLookbackDays = 30
+
+// walletBalances represents the ETH balance of each wallet.
+type walletBalances struct {
+	sync.Mutex
+
+	// Balances in ETH keyed by wallet address.
+	Balances map[string]float64
+}
+
+// parseWei parses a hexadecimal Wei amount into an ETH amount.
+func parseWei(hexAmount string) (float64, bool) {
+	wei, ok := new(big.Int).SetString(hexAmount, 0)
+	if !ok {
+		return 0, false
+	}
+	eth, _ := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e18)).Float64()
+	return eth, true
+}
+
+// OrchWalletExporter fetches the wallet balances and exposes them with the gas runway via Prometheus.
+type OrchWalletExporter struct {
+	// Metrics.
+	WalletBalance       *prometheus.GaugeVec
+	TotalBalance        prometheus.Gauge
+	AverageDailyGasCost prometheus.Gauge
+	GasRunwayDays       prometheus.Gauge
+
+	// Config settings.
+	wallets        []util.NamedAddress // The wallets to fetch the balance of.
+	fetchInterval  time.Duration       // How often to fetch data.
+	updateInterval time.Duration       // How often to update metrics.
+	rpcURL         string              // The JSON-RPC endpoint to fetch the balances from.
+
+	// Data.
+	walletBalances *walletBalances // The balances returned by the JSON-RPC endpoint.
+
+	// Data sources.
+	receiptProvider     *receipts.ReceiptProvider                  // Provides the gas costs of the transactions.
+	orchTicketsExporter *orch_tickets_exporter.OrchTicketsExporter // Provides the ticket redemptions.
+	orchRewardsExporter *orch_rewards_exporter.OrchRewardsExporter // Provides the reward calls.
+}
+
+// initMetrics initializes the wallet metrics.
+func (m *OrchWalletExporter) initMetrics() {
+	m.WalletBalance = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_wallet_balance_eth",
+			Help: "The ETH balance of each wallet.",
+		},
+		[]string{"address", "name"},
+	)
+	m.TotalBalance = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_wallet_total_balance_eth",
+			Help: "The total ETH balance of all wallets.",
+		},
+	)
+	m.AverageDailyGasCost = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_average_daily_gas_cost_eth",
+			Help: "The average daily gas cost of the ticket redemptions and reward calls in the last 30 days in ETH.",
+		},
+	)
+	m.GasRunwayDays = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_wallet_gas_runway_days",
+			Help: "The number of days the total ETH balance of all wallets covers the average daily gas cost.",
+		},
+	)
+}
+
+// registerMetrics registers the wallet metrics with Prometheus.
+func (m *OrchWalletExporter) registerMetrics() {
+	prometheus.MustRegister(
+		m.WalletBalance,
+		m.TotalBalance,
+		m.AverageDailyGasCost,
+		m.GasRunwayDays,
+	)
+}
+
+// fetchBalance fetches the ETH balance of the given address from the JSON-RPC endpoint.
+func (m *OrchWalletExporter) fetchBalance(address string) (float64, error) {
+	var hexBalance string
+	balanceFetcher := fetcher.Fetcher{
+		URL:  m.rpcURL,
+		Data: &hexBalance,
+	}
+	if err := balanceFetcher.FetchJSONRPCData("eth_getBalance", address, "latest"); err != nil {
+		return 0, err
+	}
+	balance, ok := parseWei(hexBalance)
+	if !ok {
+		return 0, fmt.Errorf("invalid balance '%s'", hexBalance)
+	}
+	return balance, nil
+}
+
+// fetchData fetches the balances of the wallets. Wallets of which the balance could not be fetched keep
+// their previous balance.
+func (m *OrchWalletExporter) fetchData() {
+	for _, wallet := range m.wallets {
+		balance, err := m.fetchBalance(wallet.Address)
+		if err != nil {
+			log.Printf("Error fetching balance of wallet '%s': %v", wallet.Address, err)
+			continue
+		}
+		m.walletBalances.Mutex.Lock()
+		m.walletBalances.Balances[wallet.Address] = balance
+		m.walletBalances.Mutex.Unlock()
+	}
+}
+
+// getAverageDailyGasCost returns the average daily gas cost of the ticket redemptions and reward calls
+// in the last gasLookbackDays days in ETH. When the first ticket redemption or reward call is more recent,
+// the gas cost is averaged over the days since then, with a minimum of one day. The returned bool is false
+// when the receipts of the transactions in the lookback window were not all fetched yet.
+func (m *OrchWalletExporter) getAverageDailyGasCost() (float64, bool) {
+	now := time.Now()
+	lookbackStart := now.AddDate(0, 0, -gasLookbackDays)
+	var ids []string
+	firstTimestamp := now
+	for _, ticket := range m.orchTicketsExporter.Tickets() {
+		if ticket.Timestamp.Before(firstTimestamp) {
+			firstTimestamp = ticket.Timestamp
+		}
+		if !ticket.Timestamp.Before(lookbackStart) {
+			ids = append(ids, ticket.ID)
+		}
+	}
+	for _, reward := range m.orchRewardsExporter.Rewards() {
+		if reward.Timestamp.Before(firstTimestamp) {
+			firstTimestamp = reward.Timestamp
+		}
+		if !reward.Timestamp.Before(lookbackStart) {
+			ids = append(ids, reward.ID)
+		}
+	}
+	gasCosts, ok := m.receiptProvider.GetGasCosts(ids)
+	if !ok {
+		return 0, false
+	}
+	var gasCost float64
+	for _, cost := range gasCosts {
+		gasCost += cost
+	}
+	if firstTimestamp.Before(lookbackStart) {
+		firstTimestamp = lookbackStart
+	}
+	return gasCost / math.Max(now.Sub(firstTimestamp).Hours()/24, 1), true
+}
+
+// updateMetrics updates the metrics with the fetched balances and the gas costs of the tickets and
+// rewards exporters.
+func (m *OrchWalletExporter) updateMetrics() {
+	var totalBalance float64
+	m.walletBalances.Mutex.Lock()
+	for _, wallet := range m.wallets {
+		balance, ok := m.walletBalances.Balances[wallet.Address]
+		if !ok {
+			continue
+		}
+		m.WalletBalance.WithLabelValues(wallet.Address, wallet.Name).Set(balance)
+		totalBalance += balance
+	}
+	m.walletBalances.Mutex.Unlock()
+	m.TotalBalance.Set(totalBalance)
+
+	// Estimate the runway from the average daily gas cost. The gas costs are only used once all winning
+	// tickets and reward events and their receipts were fetched. Without gas costs the runway is unlimited.
+	if !m.orchTicketsExporter.Complete() || !m.orchRewardsExporter.Complete() {
+		return
+	}
+	averageDailyGasCost, ok := m.getAverageDailyGasCost()
+	if !ok {
+		return
+	}
+	m.AverageDailyGasCost.Set(averageDailyGasCost)
+	if averageDailyGasCost > 0 {
+		m.GasRunwayDays.Set(totalBalance / averageDailyGasCost)
+	} else {
+		m.GasRunwayDays.Set(math.Inf(1))
+	}
+}
+
+// NewOrchWalletExporter creates a new OrchWalletExporter.
+func NewOrchWalletExporter(wallets []util.NamedAddress, rpcURL string, fetchInterval time.Duration, updateInterval time.Duration, receiptProvider *receipts.ReceiptProvider, orchTicketsExporter *orch_tickets_exporter.OrchTicketsExporter, orchRewardsExporter *orch_rewards_exporter.OrchRewardsExporter) *OrchWalletExporter {
+	exporter := &OrchWalletExporter{
+		wallets:             wallets,
+		fetchInterval:       fetchInterval,
+		updateInterval:      updateInterval,
+		rpcURL:              rpcURL,
+		walletBalances:      &walletBalances{Balances: make(map[string]float64)},
+		receiptProvider:     receiptProvider,
+		orchTicketsExporter: orchTicketsExporter,
+		orchRewardsExporter: orchRewardsExporter,
+	}
+
+	// Initialize metrics.
+	exporter.initMetrics()
+	exporter.registerMetrics()
+
+	return exporter
+}
+
+// Start starts the OrchWalletExporter.
+func (m *OrchWalletExporter) Start() {
+	// Fetch initial data and update metrics.
+	m.fetchData()
+	m.updateMetrics()
+
+	// Start fetcher in a goroutine.
+	go func() {
+		ticker := time.NewTicker(m.fetchInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			m.fetchData()
+		}
+	}()
+
+	// Start metrics updater in a goroutine.
+	go func() {
+		ticker := time.NewTicker(m.updateInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			m.updateMetrics()
+		}
+	}()
+}
@@ -0,0 +1,278 @@
+// Package orch_watchlist_exporter implements a Livepeer orchestrator watchlist exporter that fetches data
+// about a configurable list of peer orchestrators from the Livepeer subgraph GraphQL API endpoint and the
+// Livepeer orchestrator score API and exposes it via Prometheus metrics. It reuses the query and parsing
+// logic of the orch_info_exporter and the endpoint of the orch_score_exporter. The metrics have the same
+// names as the metrics of these exporters with an additional 'orchestrator' and 'name' label. Since the
+// default registry does not allow metrics with the same name but different labels, they are registered
+// with a separate registry that is gathered together with the default registry.
+package orch_watchlist_exporter
+
+import (
+	"fmt"
+	"livepeer-exporter/constants"
+	"livepeer-exporter/exporters/orch_info_exporter"
+	"livepeer-exporter/exporters/orch_score_exporter"
+	"livepeer-exporter/fetcher"
+	"livepeer-exporter/util"
+	"log"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	orchInfoEndpoint = constants.LivePeerSubgraphEndpoint
+)
+
+// watchedOrch represents an orchestrator on the watchlist together with its data and fetchers.
+type watchedOrch struct {
+	util.NamedAddress
+
+	// Data.
+	transcoderResponse *orch_info_exporter.TranscoderResponse // The data returned by the subgraph API.
+	orchScore          *orch_score_exporter.OrchScore         // The data returned by the score API.
+	orchInfo           *orch_info_exporter.OrchInfo           // The data returned by the subgraph API, parsed into a struct.
+
+	// Fetchers.
+	orchInfoFetcher  fetcher.Fetcher
+	orchScoreFetcher fetcher.Fetcher
+}
+
+// OrchWatchlistExporter fetches data from the APIs and exposes metrics about the watched orchestrators via Prometheus.
+type OrchWatchlistExporter struct {
+	// Metrics.
+	FeeCut             *prometheus.GaugeVec
+	RewardCut          *prometheus.GaugeVec
+	TotalStake         *prometheus.GaugeVec
+	OrchStake          *prometheus.GaugeVec
+	Active             *prometheus.GaugeVec
+	StakeRank          *prometheus.GaugeVec
+	ThirtyDayVolumeETH *prometheus.GaugeVec
+	RewardCallRatio    *prometheus.GaugeVec
+	PricePerPixel      *prometheus.GaugeVec
+
+	// Config settings.
+	fetchInterval  time.Duration // How often to fetch data.
+	updateInterval time.Duration // How often to update metrics.
+
+	// Data.
+	watchedOrchs []*watchedOrch // The watched orchestrators.
+
+	// Fetchers.
+	activeSetFetcher fetcher.Fetcher
+
+	// Registry the metrics are registered with.
+	registry *prometheus.Registry
+}
+
+// initMetrics initializes the watchlist metrics. The help texts must match the help texts of the metrics
+// with the same name in the other exporters, since metrics with the same name are merged when gathered.
+func (m *OrchWatchlistExporter) initMetrics() {
+	m.FeeCut = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_fee_cut",
+			Help: "The proportion of the fees the orchestrator takes.",
+		},
+		[]string{"orchestrator", "name"},
+	)
+	m.RewardCut = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_reward_cut",
+			Help: "The proportion of the block reward the orchestrator takes.",
+		},
+		[]string{"orchestrator", "name"},
+	)
+	m.TotalStake = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_total_stake",
+			Help: "The total amount of LPT that is staked to the orchestrator.",
+		},
+		[]string{"orchestrator", "name"},
+	)
+	m.OrchStake = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_stake",
+			Help: "The stake personally contributed by the orchestrator.",
+		},
+		[]string{"orchestrator", "name"},
+	)
+	m.Active = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_active",
+			Help: "Whether the orchestrator is active.",
+		},
+		[]string{"orchestrator", "name"},
+	)
+	m.StakeRank = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_stake_rank",
+			Help: "The stake rank of the orchestrator among the active orchestrators (0 if not active).",
+		},
+		[]string{"orchestrator", "name"},
+	)
+	m.ThirtyDayVolumeETH = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_thirty_day_volume_eth",
+			Help: "The 30 day volume of ETH.",
+		},
+		[]string{"orchestrator", "name"},
+	)
+	m.RewardCallRatio = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_thirty_day_reward_claim_ratio",
+			Help: "How often an orchestrator claimed rewards in the last thirty rounds.",
+		},
+		[]string{"orchestrator", "name"},
+	)
+	m.PricePerPixel = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "livepeer_orch_price_per_pixel",
+			Help: "The price per pixel in Wei.",
+		},
+		[]string{"orchestrator", "name"},
+	)
+}
+
+// registerMetrics registers the watchlist metrics with Prometheus.
+func (m *OrchWatchlistExporter) registerMetrics() {
+	m.registry.MustRegister(
+		m.FeeCut,
+		m.RewardCut,
+		m.TotalStake,
+		m.OrchStake,
+		m.Active,
+		m.StakeRank,
+		m.ThirtyDayVolumeETH,
+		m.RewardCallRatio,
+		m.PricePerPixel,
+	)
+}
+
+// fetchData fetches the data of all watched orchestrators. The active set is fetched once and shared by
+// all watched orchestrators. When it could not be fetched, the previously fetched active set is kept.
+func (m *OrchWatchlistExporter) fetchData() {
+	activeSet := &orch_info_exporter.TranscoderResponse{}
+	activeSetFetcher := m.activeSetFetcher
+	activeSetFetcher.Data = activeSet
+	activeSetErr := activeSetFetcher.FetchGraphQLData(orch_info_exporter.GetActiveSetGraphqlQuery())
+	if activeSetErr != nil {
+		log.Printf("Error fetching active set: %v", activeSetErr)
+	}
+
+	for _, orch := range m.watchedOrchs {
+		orch.transcoderResponse.Mutex.Lock()
+		orch.orchInfoFetcher.FetchGraphQLData(orch_info_exporter.GetTranscoderGraphqlQuery(orch.Address))
+		if activeSetErr == nil {
+			orch.transcoderResponse.Data.Transcoders = activeSet.Data.Transcoders
+			orch.transcoderResponse.Data.InactiveTranscoders = activeSet.Data.InactiveTranscoders
+		}
+		orch.transcoderResponse.Mutex.Unlock()
+
+		orch.orchScore.Mutex.Lock()
+		orch.orchScoreFetcher.FetchData()
+		orch.orchScore.Mutex.Unlock()
+	}
+}
+
+// updateMetrics updates the metrics with the data fetched for the watched orchestrators.
+func (m *OrchWatchlistExporter) updateMetrics() {
+	for _, orch := range m.watchedOrchs {
+		// Parse the orchestrator info from the response data.
+		orch.transcoderResponse.Mutex.Lock()
+		orch_info_exporter.ParseOrchInfo(orch.transcoderResponse, orch.orchInfo, orch.Address, "")
+		orch.transcoderResponse.Mutex.Unlock()
+
+		// Set the metrics.
+		m.FeeCut.WithLabelValues(orch.Address, orch.Name).Set(orch.orchInfo.FeeCut)
+		m.RewardCut.WithLabelValues(orch.Address, orch.Name).Set(orch.orchInfo.RewardCut)
+		m.TotalStake.WithLabelValues(orch.Address, orch.Name).Set(orch.orchInfo.TotalStake)
+		m.OrchStake.WithLabelValues(orch.Address, orch.Name).Set(orch.orchInfo.OrchStake)
+		m.Active.WithLabelValues(orch.Address, orch.Name).Set(orch.orchInfo.Active)
+		m.StakeRank.WithLabelValues(orch.Address, orch.Name).Set(orch.orchInfo.StakeRank)
+		m.ThirtyDayVolumeETH.WithLabelValues(orch.Address, orch.Name).Set(orch.orchInfo.ThirtyDayVolumeETH)
+		m.RewardCallRatio.WithLabelValues(orch.Address, orch.Name).Set(orch.orchInfo.RewardCallRatio)
+
+		orch.orchScore.Mutex.Lock()
+		m.PricePerPixel.WithLabelValues(orch.Address, orch.Name).Set(orch.orchScore.PricePerPixel)
+		orch.orchScore.Mutex.Unlock()
+	}
+}
+
+// NewOrchWatchlistExporter creates a new OrchWatchlistExporter for the given watched orchestrators.
+func NewOrchWatchlistExporter(orchAddress string, watchlist []util.NamedAddress, fetchInterval time.Duration, updateInterval time.Duration) *OrchWatchlistExporter {
+	exporter := &OrchWatchlistExporter{
+		fetchInterval:  fetchInterval,
+		updateInterval: updateInterval,
+		registry:       prometheus.NewRegistry(),
+	}
+
+	// Create request headers.
+	headers := map[string][]string{
+		"X-Device-ID": {fmt.Sprintf(constants.ClientIDTemplate, orchAddress)},
+	}
+
+	// Initialize the active set fetcher. The target is set when fetching.
+	exporter.activeSetFetcher = fetcher.Fetcher{
+		URL:     orchInfoEndpoint,
+		Headers: headers,
+	}
+
+	// Initialize the watched orchestrators and their fetchers.
+	for _, address := range watchlist {
+		orch := &watchedOrch{
+			NamedAddress:       address,
+			transcoderResponse: &orch_info_exporter.TranscoderResponse{},
+			orchScore:          &orch_score_exporter.OrchScore{},
+			orchInfo:           &orch_info_exporter.OrchInfo{},
+		}
+		orch.orchInfoFetcher = fetcher.Fetcher{
+			URL:     orchInfoEndpoint,
+			Data:    &orch.transcoderResponse,
+			Headers: headers,
+		}
+		orch.orchScoreFetcher = fetcher.Fetcher{
+			URL:     orch_score_exporter.GetOrchScoreEndpoint(address.Address),
+			Data:    &orch.orchScore,
+			Headers: headers,
+		}
+		exporter.watchedOrchs = append(exporter.watchedOrchs, orch)
+	}
+
+	// Initialize metrics.
+	exporter.initMetrics()
+	exporter.registerMetrics()
+
+	return exporter
+}
+
+// Gatherer returns the gatherer of the watchlist metrics.
+func (m *OrchWatchlistExporter) Gatherer() prometheus.Gatherer {
+	return m.registry
+}
+
+// Start starts the OrchWatchlistExporter.
+func (m *OrchWatchlistExporter) Start() {
+	// Fetch initial data and update metrics.
+	m.fetchData()
+	m.updateMetrics()
+
+	// Start fetcher in a goroutine.
+	go func() {
+		ticker := time.NewTicker(m.fetchInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			m.fetchData()
+		}
+	}()
+
+	// Start metrics updater in a goroutine.
+	go func() {
+		ticker := time.NewTicker(m.updateInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			m.updateMetrics()
+		}
+	}()
+}
@@ -0,0 +1,283 @@
+// Package protocol_exporter implements a Livepeer protocol exporter that fetches data from the Livepeer
+// subgraph GraphQL API endpoint and exposes network-wide information about the Livepeer protocol via
+// Prometheus metrics.
+package protocol_exporter
+
+import (
+	"fmt"
+	"livepeer-exporter/constants"
+	"livepeer-exporter/fetcher"
+	"livepeer-exporter/util"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	protocolEndpoint = constants.LivePeerSubgraphEndpoint
+)
+
+// graphqlQuery represents the GraphQL query to fetch data from the GraphQL API.
+const graphqlQuery = `
+{
+	protocol(id: "0") {
+		totalActiveStake
+		totalSupply
+		participationRate
+		targetBondingRate
+		inflation
+		inflationChange
+		numActiveTranscoders
+		activeTranscoderCount
+		roundLength
+		lockPeriod
+	}
+}
+`
+
+// percDivisor is the divisor used by the protocol to express percentages as integers.
+const percDivisor = 1e9
+
+// protocolResponse represents the structure of the GraphQL API response.
+type protocolResponse struct {
+	sync.Mutex
+
+	// Response data.
+	Data struct {
+		Protocol struct {
+			TotalActiveStake      string
+			TotalSupply           string
+			ParticipationRate     string
+			TargetBondingRate     string
+			Inflation             string
+			InflationChange       string
+			NumActiveTranscoders  string
+			ActiveTranscoderCount string
+			RoundLength           string
+			LockPeriod            string
+		}
+	}
+}
+
+// ProtocolInfo represents the parsed data from the Livepeer subgraph GraphQL API.
+type ProtocolInfo struct {
+	TotalBonded             float64 // The total amount of LPT bonded to active orchestrators.
+	TotalSupply             float64 // The total supply of LPT.
+	ParticipationRate       float64 // The proportion of the LPT supply that is bonded.
+	TargetParticipationRate float64 // The participation rate targeted by the inflation mechanism.
+	Inflation               float64 // The proportion of the LPT supply that is minted each round.
+	InflationChange         float64 // The change in inflation each round.
+	ActiveSetSize           float64 // The maximum number of active orchestrators.
+	ActiveTranscoderCount   float64 // The current number of active orchestrators.
+	RoundLength             float64 // The length of a round in L1 blocks.
+	LockPeriod              float64 // The number of rounds unbonded LPT is locked.
+}
+
+// ProtocolExporter fetches data from the API and exposes Livepeer protocol metrics via Prometheus.
+type ProtocolExporter struct {
+	// Metrics.
+	TotalBonded             prometheus.Gauge
+	TotalSupply             prometheus.Gauge
+	ParticipationRate       prometheus.Gauge
+	TargetParticipationRate prometheus.Gauge
+	Inflation               prometheus.Gauge
+	InflationChange         prometheus.Gauge
+	ActiveSetSize           prometheus.Gauge
+	ActiveTranscoderCount   prometheus.Gauge
+	RoundLength             prometheus.Gauge
+	LockPeriod              prometheus.Gauge
+
+	// Config settings.
+	fetchInterval        time.Duration // How often to fetch data.
+	updateInterval       time.Duration // How often to update metrics.
+	protocolEndpoint     string        // The endpoint to fetch data from.
+	protocolGraphqlQuery string        // The GraphQL query to fetch data from the GraphQL API.
+
+	// Data.
+	protocolResponse *protocolResponse // The data returned by the API.
+	protocolInfo     *ProtocolInfo     // The data returned by the API, parsed into a struct.
+
+	// Fetchers.
+	protocolFetcher fetcher.Fetcher
+}
+
+// initMetrics initializes the protocol metrics.
+func (m *ProtocolExporter) initMetrics() {
+	m.TotalBonded = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_protocol_total_bonded",
+			Help: "The total amount of LPT bonded to active orchestrators.",
+		},
+	)
+	m.TotalSupply = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_protocol_total_supply",
+			Help: "The total supply of LPT.",
+		},
+	)
+	m.ParticipationRate = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_protocol_participation_rate",
+			Help: "The proportion of the LPT supply that is bonded.",
+		},
+	)
+	m.TargetParticipationRate = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_protocol_target_participation_rate",
+			Help: "The participation rate targeted by the inflation mechanism.",
+		},
+	)
+	m.Inflation = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_protocol_inflation",
+			Help: "The proportion of the LPT supply that is minted each round.",
+		},
+	)
+	m.InflationChange = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_protocol_inflation_change",
+			Help: "The change in inflation each round.",
+		},
+	)
+	m.ActiveSetSize = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_protocol_active_set_size",
+			Help: "The maximum number of active orchestrators.",
+		},
+	)
+	m.ActiveTranscoderCount = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_protocol_active_transcoder_count",
+			Help: "The current number of active orchestrators.",
+		},
+	)
+	m.RoundLength = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_protocol_round_length",
+			Help: "The length of a round in L1 blocks.",
+		},
+	)
+	m.LockPeriod = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "livepeer_protocol_lock_period",
+			Help: "The number of rounds unbonded LPT is locked before it can be withdrawn.",
+		},
+	)
+}
+
+// registerMetrics registers the protocol metrics with Prometheus.
+func (m *ProtocolExporter) registerMetrics() {
+	prometheus.MustRegister(
+		m.TotalBonded,
+		m.TotalSupply,
+		m.ParticipationRate,
+		m.TargetParticipationRate,
+		m.Inflation,
+		m.InflationChange,
+		m.ActiveSetSize,
+		m.ActiveTranscoderCount,
+		m.RoundLength,
+		m.LockPeriod,
+	)
+}
+
+// parseMetrics parses the values from the protocolResponse and populates the protocolInfo struct.
+func (m *ProtocolExporter) parseMetrics() {
+	protocol := m.protocolResponse.Data.Protocol
+	util.SetFloatFromStr(&m.protocolInfo.TotalBonded, protocol.TotalActiveStake)
+	util.SetFloatFromStr(&m.protocolInfo.TotalSupply, protocol.TotalSupply)
+	util.SetFloatFromStr(&m.protocolInfo.ParticipationRate, protocol.ParticipationRate)
+	util.SetFloatFromStr(&m.protocolInfo.ActiveSetSize, protocol.NumActiveTranscoders)
+	util.SetFloatFromStr(&m.protocolInfo.ActiveTranscoderCount, protocol.ActiveTranscoderCount)
+	util.SetFloatFromStr(&m.protocolInfo.RoundLength, protocol.RoundLength)
+	util.SetFloatFromStr(&m.protocolInfo.LockPeriod, protocol.LockPeriod)
+
+	// Convert the percentages to proportions.
+	var targetBondingRate, inflation, inflationChange float64
+	util.SetFloatFromStr(&targetBondingRate, protocol.TargetBondingRate)
+	util.SetFloatFromStr(&inflation, protocol.Inflation)
+	util.SetFloatFromStr(&inflationChange, protocol.InflationChange)
+	m.protocolInfo.TargetParticipationRate = targetBondingRate / percDivisor
+	m.protocolInfo.Inflation = inflation / percDivisor
+	m.protocolInfo.InflationChange = inflationChange / percDivisor
+}
+
+// updateMetrics updates the metrics with the data fetched from the Livepeer subgraph GraphQL API.
+func (m *ProtocolExporter) updateMetrics() {
+	// Parse the metrics from the response data.
+	m.protocolResponse.Mutex.Lock()
+	m.parseMetrics()
+	m.protocolResponse.Mutex.Unlock()
+
+	// Set the metrics.
+	m.TotalBonded.Set(m.protocolInfo.TotalBonded)
+	m.TotalSupply.Set(m.protocolInfo.TotalSupply)
+	m.ParticipationRate.Set(m.protocolInfo.ParticipationRate)
+	m.TargetParticipationRate.Set(m.protocolInfo.TargetParticipationRate)
+	m.Inflation.Set(m.protocolInfo.Inflation)
+	m.InflationChange.Set(m.protocolInfo.InflationChange)
+	m.ActiveSetSize.Set(m.protocolInfo.ActiveSetSize)
+	m.ActiveTranscoderCount.Set(m.protocolInfo.ActiveTranscoderCount)
+	m.RoundLength.Set(m.protocolInfo.RoundLength)
+	m.LockPeriod.Set(m.protocolInfo.LockPeriod)
+}
+
+// NewProtocolExporter creates a new ProtocolExporter.
+func NewProtocolExporter(orchAddress string, fetchInterval time.Duration, updateInterval time.Duration) *ProtocolExporter {
+	exporter := &ProtocolExporter{
+		fetchInterval:        fetchInterval,
+		updateInterval:       updateInterval,
+		protocolEndpoint:     protocolEndpoint,
+		protocolGraphqlQuery: graphqlQuery,
+		protocolResponse:     &protocolResponse{},
+		protocolInfo:         &ProtocolInfo{},
+	}
+
+	// Create request headers.
+	headers := map[string][]string{
+		"X-Device-ID": {fmt.Sprintf(constants.ClientIDTemplate, orchAddress)},
+	}
+
+	// Initialize fetcher.
+	exporter.protocolFetcher = fetcher.Fetcher{
+		URL:     exporter.protocolEndpoint,
+		Data:    &exporter.protocolResponse,
+		Headers: headers,
+	}
+
+	// Initialize metrics.
+	exporter.initMetrics()
+	exporter.registerMetrics()
+
+	return exporter
+}
+
+// Start starts the ProtocolExporter.
+func (m *ProtocolExporter) Start() {
+	// Fetch initial data and update metrics.
+	m.protocolFetcher.FetchGraphQLData(m.protocolGraphqlQuery)
+	m.updateMetrics()
+
+	// Start fetcher in a goroutine.
+	go func() {
+		ticker := time.NewTicker(m.fetchInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			m.protocolResponse.Mutex.Lock()
+			m.protocolFetcher.FetchGraphQLData(m.protocolGraphqlQuery)
+			m.protocolResponse.Mutex.Unlock()
+		}
+	}()
+
+	// Start metrics updater in a goroutine.
+	go func() {
+		ticker := time.NewTicker(m.updateInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			m.updateMetrics()
+		}
+	}()
+}
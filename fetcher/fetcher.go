@@ -58,7 +58,8 @@ func (f *Fetcher) FetchData() error {
 
 // FetchGraphQLData fetches GraphQL data from the Fetcher's URL with the provided query and unmarshals
 // it into the Fetcher's Data field. It returns an error if there was an issue fetching the data, if
-// the HTTP status code is not 200, or if there was an issue decoding the response body.
+// the HTTP status code is not 200, if there was an issue decoding the response body or if the response
+// contains GraphQL errors, in which case the data may be partial.
 func (f *Fetcher) FetchGraphQLData(query string) error {
 	requestBody, err := json.Marshal(map[string]string{
 		"query": query,
@@ -94,10 +95,91 @@ func (f *Fetcher) FetchGraphQLData(query string) error {
 		return fmt.Errorf("received non-200 status code: %d", resp.StatusCode)
 	}
 
+	var body json.RawMessage
 	dec := json.NewDecoder(resp.Body)
-	if err := dec.Decode(&f.Data); err != nil {
+	if err := dec.Decode(&body); err != nil {
+		return fmt.Errorf("error decoding response body from '%s': %w", f.URL, err)
+	}
+	if err := json.Unmarshal(body, &f.Data); err != nil {
+		return fmt.Errorf("error decoding response body from '%s': %w", f.URL, err)
+	}
+
+	// Check for GraphQL errors.
+	var response struct {
+		Errors []struct {
+			Message string
+		}
+	}
+	if err := json.Unmarshal(body, &response); err == nil && len(response.Errors) > 0 {
+		return fmt.Errorf("GraphQL request to '%s' returned error: %s", f.URL, response.Errors[0].Message)
+	}
+
+	return nil
+}
+
+// FetchJSONRPCData calls the given JSON-RPC method with the provided params on the Fetcher's URL and
+// unmarshals the result into the Fetcher's Data field. It returns an error if there was an issue fetching
+// the data, if the HTTP status code is not 200, if the server returned an error or if there was an issue
+// decoding the response body.
+func (f *Fetcher) FetchJSONRPCData(method string, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	requestBody, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating request body: %v", err)
+	}
+
+	// Create a new request with the provided data.
+	req, err := http.NewRequest("POST", f.URL, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Add additional headers, if any.
+	if f.Headers != nil {
+		for name, values := range f.Headers {
+			for _, value := range values {
+				req.Header.Add(name, value)
+			}
+		}
+	}
+
+	// Send the request.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error making JSON-RPC request to '%s': %w", f.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("received non-200 status code: %d", resp.StatusCode)
+	}
+
+	// Decode the response and unmarshal the result into the Fetcher's Data field.
+	var response struct {
+		Result json.RawMessage
+		Error  *struct {
+			Code    int
+			Message string
+		}
+	}
+	dec := json.NewDecoder(resp.Body)
+	if err := dec.Decode(&response); err != nil {
 		return fmt.Errorf("error decoding response body from '%s': %w", f.URL, err)
 	}
+	if response.Error != nil {
+		return fmt.Errorf("JSON-RPC method '%s' returned error %d: %s", method, response.Error.Code, response.Error.Message)
+	}
+	if err := json.Unmarshal(response.Result, &f.Data); err != nil {
+		return fmt.Errorf("error decoding result of JSON-RPC method '%s': %w", method, err)
+	}
 
 	return nil
 }
@@ -3,6 +3,14 @@
 // It fetches various Livepeer metrics from different endpoints and exposes them via an HTTP server.
 // The server provides a '8954/metrics' endpoint for Prometheus to scrape.
 //
+// The exporter also serves a '9153/export/earnings.csv' endpoint with a tax-ready earnings report
+// of the orchestrator. This report can also be written to a file by running the exporter with the
+// 'export' command (i.e. 'livepeer-exporter export -output earnings.csv -currency EUR').
+//
+// The '9153/delegators/earnings' endpoint serves the estimated earnings of each delegator as JSON and
+// the '9153/simulate?reward_cut=0.1&fee_cut=0.2' endpoint projects the orchestrator's income and the
+// delegator APR under the given cuts.
+//
 // The exporter has the following configuration environment variables:
 //   - LIVEPEER_EXPORTER_ORCHESTRATOR_ADDRESS - The address of the orchestrator to fetch data from.
 //   - LIVEPEER_EXPORTER_ORCHESTRATOR_ADDRESS_SECONDARY - The address of the secondary orchestrator to fetch data from. Used to
@@ -14,6 +22,15 @@
 //   - LIVEPEER_EXPORTER_TICKETS_FETCH_INTERVAL - How often to fetch tickets data for the orchestrator.
 //   - LIVEPEER_EXPORTER_REWARDS_FETCH_INTERVAL - How often to fetch rewards data for the orchestrator.
 //   - LIVEPEER_EXPORTER_CRYPTO_PRICES_FETCH_INTERVAL - How often to fetch crypto prices.
+//   - LIVEPEER_EXPORTER_PROTOCOL_FETCH_INTERVAL - How often to fetch Livepeer protocol data.
+//   - LIVEPEER_EXPORTER_WATCHLIST_FETCH_INTERVAL - How often to fetch data for the watched orchestrators.
+//   - LIVEPEER_EXPORTER_NETWORK_PRICING_FETCH_INTERVAL - How often to fetch the prices of all active orchestrators.
+//   - LIVEPEER_EXPORTER_BOND_EVENTS_FETCH_INTERVAL - How often to fetch the bond events of the orchestrator's delegators.
+//   - LIVEPEER_EXPORTER_UNBONDING_FETCH_INTERVAL - How often to fetch the unbonding locks of the orchestrator's delegators.
+//   - LIVEPEER_EXPORTER_FEE_WITHDRAWALS_FETCH_INTERVAL - How often to fetch the fee withdrawals of the orchestrator.
+//   - LIVEPEER_EXPORTER_WALLET_FETCH_INTERVAL - How often to fetch the ETH balances of the wallets.
+//   - LIVEPEER_EXPORTER_GAS_FETCH_INTERVAL - How often to fetch the Arbitrum gas fees and the receipts of the orchestrator's transactions.
+//   - LIVEPEER_EXPORTER_CUT_HISTORY_FETCH_INTERVAL - How often to fetch the cut change history of the orchestrator and the watched orchestrators.
 //   - LIVEPEER_EXPORTER_INFO_UPDATE_INTERVAL - How often to update the orchestrator info metrics.
 //   - LIVEPEER_EXPORTER_SCORE_UPDATE_INTERVAL - How often to update the orchestrator score metrics.
 //   - LIVEPEER_EXPORTER_DELEGATORS_UPDATE_INTERVAL - How often to update the orchestrator delegators metrics.
@@ -21,47 +38,181 @@
 //   - LIVEPEER_EXPORTER_TICKETS_UPDATE_INTERVAL - How often to update the orchestrator tickets metrics.
 //   - LIVEPEER_EXPORTER_REWARDS_UPDATE_INTERVAL - How often to update the orchestrator rewards metrics.
 //   - LIVEPEER_EXPORTER_CRYPTO_PRICES_UPDATE_INTERVAL - How often to update the crypto prices metrics.
+//   - LIVEPEER_EXPORTER_PROTOCOL_UPDATE_INTERVAL - How often to update the Livepeer protocol metrics.
+//   - LIVEPEER_EXPORTER_WATCHLIST_UPDATE_INTERVAL - How often to update the watched orchestrators metrics.
+//   - LIVEPEER_EXPORTER_NETWORK_PRICING_UPDATE_INTERVAL - How often to update the network pricing metrics.
+//   - LIVEPEER_EXPORTER_BOND_EVENTS_UPDATE_INTERVAL - How often to update the bond events metrics.
+//   - LIVEPEER_EXPORTER_UNBONDING_UPDATE_INTERVAL - How often to update the unbonding metrics.
+//   - LIVEPEER_EXPORTER_FEE_WITHDRAWALS_UPDATE_INTERVAL - How often to update the fee withdrawal metrics.
+//   - LIVEPEER_EXPORTER_WALLET_UPDATE_INTERVAL - How often to update the wallet metrics.
+//   - LIVEPEER_EXPORTER_GAS_UPDATE_INTERVAL - How often to update the gas metrics.
+//   - LIVEPEER_EXPORTER_CUT_HISTORY_UPDATE_INTERVAL - How often to update the cut history metrics.
+//   - LIVEPEER_EXPORTER_WATCHLIST - Comma-separated list of peer orchestrators to watch in the 'address:name' format. The name is optional.
+//   - LIVEPEER_EXPORTER_WALLETS - Comma-separated list of wallets to fetch the ETH balance of in the 'address:name' format. Defaults to the orchestrator address.
+//   - LIVEPEER_EXPORTER_RPC_URL - The Arbitrum JSON-RPC endpoint used to fetch on-chain data.
+//   - LIVEPEER_EXPORTER_PROFIT_UPDATE_INTERVAL - How often to update the orchestrator profit metrics.
+//   - LIVEPEER_EXPORTER_REWARD_PROFITABILITY_UPDATE_INTERVAL - How often to update the reward profitability metrics.
+//   - LIVEPEER_EXPORTER_FORECAST_UPDATE_INTERVAL - How often to update the earnings forecast metrics.
+//   - LIVEPEER_EXPORTER_FORECAST_LOOKBACK_DAYS - The number of days of history the earnings forecasts are based on.
+//   - LIVEPEER_EXPORTER_FORECAST_METHOD - The earnings forecast method, either 'moving_average' or 'linear_trend'.
+//   - LIVEPEER_EXPORTER_APR_LOOKBACK_ROUNDS - Comma-separated list of the number of rounds over which the delegator APR and APY are estimated.
+//   - LIVEPEER_EXPORTER_POOL_HISTORY_ROUNDS - The number of rounds of reward pool history to expose.
+//   - LIVEPEER_EXPORTER_REWARD_CALL_RATIO_WINDOWS - Comma-separated list of the number of rounds over which the reward call ratio is calculated.
+//   - LIVEPEER_EXPORTER_BOND_EVENTS_TOP_N - The number of largest recent stake movements to expose.
+//   - LIVEPEER_EXPORTER_TICKETS_SENDERS_TOP_N - The number of ticket senders with the highest fees to expose.
+//   - LIVEPEER_EXPORTER_TICKETS_COSTLY_REDEMPTION_RATIO - The gas cost to face value ratio above which a ticket redemption is considered costly.
+//   - LIVEPEER_EXPORTER_BOND_EVENTS_LOG_THRESHOLD - The LPT amount above which new stake movements are logged. Logging is disabled when not set.
+//   - LIVEPEER_EXPORTER_EARNINGS_CURRENCY - The fiat currency used to value the earnings in the earnings report.
+//   - LIVEPEER_EXPORTER_PRICE_CACHE_PATH - The file used to cache the historical crypto prices. When not set, prices are only cached in memory.
 package main
 
 import (
+	"flag"
+	"fmt"
+	"livepeer-exporter/earnings"
 	"livepeer-exporter/exporters/crypto_prices_exporter"
+	"livepeer-exporter/exporters/network_pricing_exporter"
+	"livepeer-exporter/exporters/orch_bond_events_exporter"
+	"livepeer-exporter/exporters/orch_cut_history_exporter"
 	"livepeer-exporter/exporters/orch_delegators_exporter"
+	"livepeer-exporter/exporters/orch_fee_withdrawals_exporter"
+	"livepeer-exporter/exporters/orch_forecast_exporter"
+	"livepeer-exporter/exporters/orch_gas_exporter"
 	"livepeer-exporter/exporters/orch_info_exporter"
+	"livepeer-exporter/exporters/orch_profit_exporter"
+	"livepeer-exporter/exporters/orch_reward_profitability_exporter"
 	"livepeer-exporter/exporters/orch_rewards_exporter"
 	"livepeer-exporter/exporters/orch_score_exporter"
 	"livepeer-exporter/exporters/orch_test_streams_exporter"
 	"livepeer-exporter/exporters/orch_tickets_exporter"
+	"livepeer-exporter/exporters/orch_unbonding_exporter"
+	"livepeer-exporter/exporters/orch_wallet_exporter"
+	"livepeer-exporter/exporters/orch_watchlist_exporter"
+	"livepeer-exporter/exporters/protocol_exporter"
+	"livepeer-exporter/prices"
+	"livepeer-exporter/receipts"
+	"livepeer-exporter/simulator"
 	"livepeer-exporter/util"
 	"log"
+	"math"
 	"net/http"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // Exporter default config values.
 var (
 	// Fetch intervals.
-	infoFetchIntervalDefault        = 2 * time.Minute
-	scoreFetchIntervalDefault       = 15 * time.Minute
-	delegatorsFetchIntervalDefault  = 15 * time.Minute
-	testStreamsFetchIntervalDefault = 15 * time.Minute
-	ticketsFetchIntervalDefault     = 15 * time.Minute
-	rewardsFetchIntervalDefault     = 15 * time.Minute
-	cryptoPricesFetchInterval       = 1 * time.Minute
+	infoFetchIntervalDefault           = 2 * time.Minute
+	scoreFetchIntervalDefault          = 15 * time.Minute
+	delegatorsFetchIntervalDefault     = 15 * time.Minute
+	testStreamsFetchIntervalDefault    = 15 * time.Minute
+	ticketsFetchIntervalDefault        = 15 * time.Minute
+	rewardsFetchIntervalDefault        = 15 * time.Minute
+	cryptoPricesFetchInterval          = 1 * time.Minute
+	protocolFetchIntervalDefault       = 15 * time.Minute
+	watchlistFetchIntervalDefault      = 1 * time.Hour
+	networkPricingFetchIntervalDefault = 1 * time.Hour
+	bondEventsFetchIntervalDefault     = 15 * time.Minute
+	unbondingFetchIntervalDefault      = 15 * time.Minute
+	feeWithdrawalsFetchIntervalDefault = 15 * time.Minute
+	walletFetchIntervalDefault         = 5 * time.Minute
+	gasFetchIntervalDefault            = 1 * time.Minute
+	cutHistoryFetchIntervalDefault     = 1 * time.Hour
 
 	// Update intervals.
-	infoUpdateIntervalDefault         = 1 * time.Minute
-	scoreUpdateIntervalDefault        = 1 * time.Minute
-	delegatorsUpdateIntervalDefault   = 1 * time.Minute
-	testStreamsUpdateIntervalDefault  = 1 * time.Minute
-	ticketsUpdateIntervalDefault      = 1 * time.Minute
-	rewardsUpdateIntervalDefault      = 1 * time.Minute
-	cryptoPricesUpdateIntervalDefault = 1 * time.Minute
+	infoUpdateIntervalDefault                = 1 * time.Minute
+	scoreUpdateIntervalDefault               = 1 * time.Minute
+	delegatorsUpdateIntervalDefault          = 1 * time.Minute
+	testStreamsUpdateIntervalDefault         = 1 * time.Minute
+	ticketsUpdateIntervalDefault             = 1 * time.Minute
+	rewardsUpdateIntervalDefault             = 1 * time.Minute
+	cryptoPricesUpdateIntervalDefault        = 1 * time.Minute
+	profitUpdateIntervalDefault              = 1 * time.Minute
+	rewardProfitabilityUpdateIntervalDefault = 1 * time.Minute
+	forecastUpdateIntervalDefault            = 5 * time.Minute
+	protocolUpdateIntervalDefault            = 1 * time.Minute
+	watchlistUpdateIntervalDefault           = 5 * time.Minute
+	networkPricingUpdateIntervalDefault      = 5 * time.Minute
+	bondEventsUpdateIntervalDefault          = 1 * time.Minute
+	unbondingUpdateIntervalDefault           = 1 * time.Minute
+	feeWithdrawalsUpdateIntervalDefault      = 1 * time.Minute
+	walletUpdateIntervalDefault              = 1 * time.Minute
+	gasUpdateIntervalDefault                 = 1 * time.Minute
+	cutHistoryUpdateIntervalDefault          = 5 * time.Minute
+
+	// JSON-RPC settings.
+	rpcURLDefault = "https://arb1.arbitrum.io/rpc"
+
+	// Earnings report settings.
+	earningsCurrencyDefault = "USD"
+
+	// Delegator yield settings.
+	aprLookbackRoundsDefault = []int{30, 90}
+
+	// Ticket settings.
+	ticketsSendersTopNDefault           = 10
+	ticketsCostlyRedemptionRatioDefault = 0.1
+
+	// Pool history settings.
+	poolHistoryRoundsDefault = 30
+
+	// Reward call settings.
+	rewardCallRatioWindowsDefault = []int{7, 30, 90}
+
+	// Earnings forecast settings.
+	forecastLookbackDaysDefault = 90
+	forecastMethodDefault       = orch_forecast_exporter.MethodMovingAverage
+
+	// Bond events settings.
+	bondEventsTopNDefault         = 10
+	bondEventsLogThresholdDefault = 0.0
 )
 
+// runExport fetches the orchestrator's winning tickets and rewards and writes the earnings report
+// to the output file given in the command line arguments or, if not set, to stdout. The output file
+// is removed when the report could not be written completely.
+func runExport(args []string, orchTicketsExporter *orch_tickets_exporter.OrchTicketsExporter, orchRewardsExporter *orch_rewards_exporter.OrchRewardsExporter, priceProvider *prices.HistoricalPriceProvider, defaultCurrency string) error {
+	exportFlags := flag.NewFlagSet("export", flag.ExitOnError)
+	output := exportFlags.String("output", "", "The file to write the earnings report to (default stdout)")
+	currency := exportFlags.String("currency", defaultCurrency, "The fiat currency used to value the earnings")
+	exportFlags.Parse(args)
+
+	// Fetch the earnings data.
+	if err := orchTicketsExporter.Fetch(); err != nil {
+		return fmt.Errorf("error fetching winning tickets: %w", err)
+	}
+	if err := orchRewardsExporter.Fetch(); err != nil {
+		return fmt.Errorf("error fetching rewards: %w", err)
+	}
+
+	// Write the earnings report.
+	report := earnings.NewEarningsReport(strings.ToUpper(*currency), orchTicketsExporter, orchRewardsExporter, priceProvider)
+	if *output == "" {
+		if err := report.WriteCSV(os.Stdout); err != nil {
+			return fmt.Errorf("error writing earnings report: %w", err)
+		}
+		return nil
+	}
+	file, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("error creating output file '%v': %w", *output, err)
+	}
+	err = report.WriteCSV(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(*output)
+		return fmt.Errorf("error writing earnings report: %w", err)
+	}
+	return nil
+}
+
 // Default config values.
 func main() {
 	log.Println("Starting Livepeer exporter...")
@@ -97,6 +248,15 @@ func main() {
 	ticketsFetchInterval := util.GetEnvDuration("LIVEPEER_EXPORTER_TICKETS_FETCH_INTERVAL", ticketsFetchIntervalDefault)
 	rewardsFetchInterval := util.GetEnvDuration("LIVEPEER_EXPORTER_REWARDS_FETCH_INTERVAL", rewardsFetchIntervalDefault)
 	cryptoPricesFetchInterval := util.GetEnvDuration("LIVEPEER_EXPORTER_CRYPTO_PRICES_FETCH_INTERVAL", cryptoPricesFetchInterval)
+	protocolFetchInterval := util.GetEnvDuration("LIVEPEER_EXPORTER_PROTOCOL_FETCH_INTERVAL", protocolFetchIntervalDefault)
+	watchlistFetchInterval := util.GetEnvDuration("LIVEPEER_EXPORTER_WATCHLIST_FETCH_INTERVAL", watchlistFetchIntervalDefault)
+	networkPricingFetchInterval := util.GetEnvDuration("LIVEPEER_EXPORTER_NETWORK_PRICING_FETCH_INTERVAL", networkPricingFetchIntervalDefault)
+	bondEventsFetchInterval := util.GetEnvDuration("LIVEPEER_EXPORTER_BOND_EVENTS_FETCH_INTERVAL", bondEventsFetchIntervalDefault)
+	unbondingFetchInterval := util.GetEnvDuration("LIVEPEER_EXPORTER_UNBONDING_FETCH_INTERVAL", unbondingFetchIntervalDefault)
+	feeWithdrawalsFetchInterval := util.GetEnvDuration("LIVEPEER_EXPORTER_FEE_WITHDRAWALS_FETCH_INTERVAL", feeWithdrawalsFetchIntervalDefault)
+	walletFetchInterval := util.GetEnvDuration("LIVEPEER_EXPORTER_WALLET_FETCH_INTERVAL", walletFetchIntervalDefault)
+	gasFetchInterval := util.GetEnvDuration("LIVEPEER_EXPORTER_GAS_FETCH_INTERVAL", gasFetchIntervalDefault)
+	cutHistoryFetchInterval := util.GetEnvDuration("LIVEPEER_EXPORTER_CUT_HISTORY_FETCH_INTERVAL", cutHistoryFetchIntervalDefault)
 
 	// Retrieve update intervals.
 	infoUpdateInterval := util.GetEnvDuration("LIVEPEER_EXPORTER_INFO_UPDATE_INTERVAL", infoUpdateIntervalDefault)
@@ -106,16 +266,114 @@ func main() {
 	ticketsUpdateInterval := util.GetEnvDuration("LIVEPEER_EXPORTER_TICKETS_UPDATE_INTERVAL", ticketsUpdateIntervalDefault)
 	rewardsUpdateInterval := util.GetEnvDuration("LIVEPEER_EXPORTER_REWARDS_UPDATE_INTERVAL", rewardsUpdateIntervalDefault)
 	cryptoPricesUpdateInterval := util.GetEnvDuration("LIVEPEER_EXPORTER_CRYPTO_PRICES_UPDATE_INTERVAL", cryptoPricesUpdateIntervalDefault)
+	profitUpdateInterval := util.GetEnvDuration("LIVEPEER_EXPORTER_PROFIT_UPDATE_INTERVAL", profitUpdateIntervalDefault)
+	rewardProfitabilityUpdateInterval := util.GetEnvDuration("LIVEPEER_EXPORTER_REWARD_PROFITABILITY_UPDATE_INTERVAL", rewardProfitabilityUpdateIntervalDefault)
+	forecastUpdateInterval := util.GetEnvDuration("LIVEPEER_EXPORTER_FORECAST_UPDATE_INTERVAL", forecastUpdateIntervalDefault)
+	protocolUpdateInterval := util.GetEnvDuration("LIVEPEER_EXPORTER_PROTOCOL_UPDATE_INTERVAL", protocolUpdateIntervalDefault)
+	watchlistUpdateInterval := util.GetEnvDuration("LIVEPEER_EXPORTER_WATCHLIST_UPDATE_INTERVAL", watchlistUpdateIntervalDefault)
+	networkPricingUpdateInterval := util.GetEnvDuration("LIVEPEER_EXPORTER_NETWORK_PRICING_UPDATE_INTERVAL", networkPricingUpdateIntervalDefault)
+	bondEventsUpdateInterval := util.GetEnvDuration("LIVEPEER_EXPORTER_BOND_EVENTS_UPDATE_INTERVAL", bondEventsUpdateIntervalDefault)
+	unbondingUpdateInterval := util.GetEnvDuration("LIVEPEER_EXPORTER_UNBONDING_UPDATE_INTERVAL", unbondingUpdateIntervalDefault)
+	feeWithdrawalsUpdateInterval := util.GetEnvDuration("LIVEPEER_EXPORTER_FEE_WITHDRAWALS_UPDATE_INTERVAL", feeWithdrawalsUpdateIntervalDefault)
+	walletUpdateInterval := util.GetEnvDuration("LIVEPEER_EXPORTER_WALLET_UPDATE_INTERVAL", walletUpdateIntervalDefault)
+	gasUpdateInterval := util.GetEnvDuration("LIVEPEER_EXPORTER_GAS_UPDATE_INTERVAL", gasUpdateIntervalDefault)
+	cutHistoryUpdateInterval := util.GetEnvDuration("LIVEPEER_EXPORTER_CUT_HISTORY_UPDATE_INTERVAL", cutHistoryUpdateIntervalDefault)
+
+	// Retrieve the watched orchestrators.
+	watchlist := util.ParseNamedAddresses(os.Getenv("LIVEPEER_EXPORTER_WATCHLIST"))
+
+	// Retrieve the wallets and the JSON-RPC endpoint.
+	wallets := util.ParseNamedAddresses(os.Getenv("LIVEPEER_EXPORTER_WALLETS"))
+	if len(wallets) == 0 {
+		wallets = []util.NamedAddress{{Address: orchAddr, Name: orchAddr}}
+	}
+	rpcURL := util.GetEnvString("LIVEPEER_EXPORTER_RPC_URL", rpcURLDefault)
+
+	// Retrieve the delegator yield lookback windows.
+	aprLookbackRounds := util.GetEnvIntSlice("LIVEPEER_EXPORTER_APR_LOOKBACK_ROUNDS", aprLookbackRoundsDefault)
+	for _, window := range aprLookbackRounds {
+		if window <= 0 {
+			log.Fatalf("Invalid LIVEPEER_EXPORTER_APR_LOOKBACK_ROUNDS window '%d', expected a positive number", window)
+		}
+	}
+
+	// Retrieve the pool history lookback.
+	poolHistoryRounds := util.GetEnvInt("LIVEPEER_EXPORTER_POOL_HISTORY_ROUNDS", poolHistoryRoundsDefault)
+
+	// Retrieve the reward call ratio windows.
+	rewardCallRatioWindows := util.GetEnvIntSlice("LIVEPEER_EXPORTER_REWARD_CALL_RATIO_WINDOWS", rewardCallRatioWindowsDefault)
+	for _, window := range rewardCallRatioWindows {
+		if window <= 0 || window > orch_info_exporter.PoolsLimit {
+			log.Fatalf("Invalid LIVEPEER_EXPORTER_REWARD_CALL_RATIO_WINDOWS window '%d', expected a positive number of at most %d", window, orch_info_exporter.PoolsLimit)
+		}
+	}
+
+	// Retrieve earnings forecast settings.
+	forecastLookbackDays := util.GetEnvInt("LIVEPEER_EXPORTER_FORECAST_LOOKBACK_DAYS", forecastLookbackDaysDefault)
+	if forecastLookbackDays <= 0 {
+		log.Fatalf("Invalid LIVEPEER_EXPORTER_FORECAST_LOOKBACK_DAYS '%d', expected a positive number", forecastLookbackDays)
+	}
+	forecastMethod := util.GetEnvString("LIVEPEER_EXPORTER_FORECAST_METHOD", forecastMethodDefault)
+	if forecastMethod != orch_forecast_exporter.MethodMovingAverage && forecastMethod != orch_forecast_exporter.MethodLinearTrend {
+		log.Fatalf("Invalid forecast method '%s', expected '%s' or '%s'", forecastMethod, orch_forecast_exporter.MethodMovingAverage, orch_forecast_exporter.MethodLinearTrend)
+	}
+
+	// Retrieve ticket settings.
+	ticketsSendersTopN := util.GetEnvInt("LIVEPEER_EXPORTER_TICKETS_SENDERS_TOP_N", ticketsSendersTopNDefault)
+	if ticketsSendersTopN < 0 {
+		log.Fatalf("Invalid LIVEPEER_EXPORTER_TICKETS_SENDERS_TOP_N '%d', expected a non-negative number", ticketsSendersTopN)
+	}
+	ticketsCostlyRedemptionRatio := util.GetEnvFloat("LIVEPEER_EXPORTER_TICKETS_COSTLY_REDEMPTION_RATIO", ticketsCostlyRedemptionRatioDefault)
+	if ticketsCostlyRedemptionRatio < 0 || math.IsNaN(ticketsCostlyRedemptionRatio) {
+		log.Fatalf("Invalid LIVEPEER_EXPORTER_TICKETS_COSTLY_REDEMPTION_RATIO '%v', expected a non-negative number", ticketsCostlyRedemptionRatio)
+	}
+
+	// Retrieve bond events settings.
+	bondEventsTopN := util.GetEnvInt("LIVEPEER_EXPORTER_BOND_EVENTS_TOP_N", bondEventsTopNDefault)
+	if bondEventsTopN < 0 {
+		log.Fatalf("Invalid LIVEPEER_EXPORTER_BOND_EVENTS_TOP_N '%d', expected a non-negative number", bondEventsTopN)
+	}
+	bondEventsLogThreshold := util.GetEnvFloat("LIVEPEER_EXPORTER_BOND_EVENTS_LOG_THRESHOLD", bondEventsLogThresholdDefault)
+
+	// Retrieve earnings report settings.
+	earningsCurrency := strings.ToUpper(util.GetEnvString("LIVEPEER_EXPORTER_EARNINGS_CURRENCY", earningsCurrencyDefault))
+	priceCachePath := os.Getenv("LIVEPEER_EXPORTER_PRICE_CACHE_PATH")
+	priceProvider := prices.NewHistoricalPriceProvider(priceCachePath)
+	receiptProvider := receipts.NewReceiptProvider(rpcURL)
 
 	// Setup sub-exporters.
 	log.Println("Setting up sub exporters...")
-	orchInfoExporter := orch_info_exporter.NewOrchInfoExporter(orchAddr, infoFetchInterval, infoUpdateInterval, orchAddrSecondary)
+	cryptoPricesExporter := crypto_prices_exporter.NewCryptoPricesExporter(cryptoPricesFetchInterval, cryptoPricesUpdateInterval)
+	orchInfoExporter := orch_info_exporter.NewOrchInfoExporter(orchAddr, infoFetchInterval, infoUpdateInterval, orchAddrSecondary, aprLookbackRounds, poolHistoryRounds, rewardCallRatioWindows, cryptoPricesExporter)
 	orchScoreExporter := orch_score_exporter.NewOrchScoreExporter(orchAddr, scoreFetchInterval, scoreUpdateInterval)
-	orchDelegatorsExporter := orch_delegators_exporter.NewOrchDelegatorsExporter(orchAddr, delegatorsFetchInterval, delegatorsUpdateInterval)
 	orchTestStreamsExporter := orch_test_streams_exporter.NewOrchTestStreamsExporter(orchAddr, testStreamFetchInterval, testStreamUpdateInterval)
-	orchTicketsExporter := orch_tickets_exporter.NewOrchTicketsExporter(orchAddr, ticketsFetchInterval, ticketsUpdateInterval)
-	orchRewardsExporter := orch_rewards_exporter.NewOrchRewardsExporter(orchAddr, rewardsFetchInterval, rewardsUpdateInterval)
-	cryptoPricesExporter := crypto_prices_exporter.NewCryptoPricesExporter(cryptoPricesFetchInterval, cryptoPricesUpdateInterval)
+	orchTicketsExporter := orch_tickets_exporter.NewOrchTicketsExporter(orchAddr, ticketsFetchInterval, ticketsUpdateInterval, ticketsSendersTopN, ticketsCostlyRedemptionRatio, receiptProvider)
+	orchRewardsExporter := orch_rewards_exporter.NewOrchRewardsExporter(orchAddr, rewardsFetchInterval, rewardsUpdateInterval, poolHistoryRounds)
+
+	orchDelegatorsExporter := orch_delegators_exporter.NewOrchDelegatorsExporter(orchAddr, delegatorsFetchInterval, delegatorsUpdateInterval, orchAddrSecondary, orchInfoExporter, orchRewardsExporter)
+	orchProfitExporter := orch_profit_exporter.NewOrchProfitExporter(profitUpdateInterval, receiptProvider, orchInfoExporter, orchTicketsExporter, orchRewardsExporter)
+	orchForecastExporter := orch_forecast_exporter.NewOrchForecastExporter(forecastUpdateInterval, forecastLookbackDays, forecastMethod, orchTicketsExporter, orchRewardsExporter)
+	protocolExporter := protocol_exporter.NewProtocolExporter(orchAddr, protocolFetchInterval, protocolUpdateInterval)
+	networkPricingExporter := network_pricing_exporter.NewNetworkPricingExporter(orchAddr, networkPricingFetchInterval, networkPricingUpdateInterval)
+	orchBondEventsExporter := orch_bond_events_exporter.NewOrchBondEventsExporter(orchAddr, bondEventsFetchInterval, bondEventsUpdateInterval, bondEventsTopN, bondEventsLogThreshold)
+	orchUnbondingExporter := orch_unbonding_exporter.NewOrchUnbondingExporter(orchAddr, unbondingFetchInterval, unbondingUpdateInterval, orchAddrSecondary)
+	orchFeeWithdrawalsExporter := orch_fee_withdrawals_exporter.NewOrchFeeWithdrawalsExporter(orchAddr, feeWithdrawalsFetchInterval, feeWithdrawalsUpdateInterval, receiptProvider)
+	orchWalletExporter := orch_wallet_exporter.NewOrchWalletExporter(wallets, rpcURL, walletFetchInterval, walletUpdateInterval, receiptProvider, orchTicketsExporter, orchRewardsExporter)
+	orchGasExporter := orch_gas_exporter.NewOrchGasExporter(rpcURL, gasFetchInterval, gasUpdateInterval, receiptProvider, orchTicketsExporter, orchRewardsExporter)
+	orchRewardProfitabilityExporter := orch_reward_profitability_exporter.NewOrchRewardProfitabilityExporter(rewardProfitabilityUpdateInterval, receiptProvider, priceProvider, orchRewardsExporter, orchGasExporter, cryptoPricesExporter)
+	orchCutHistoryExporter := orch_cut_history_exporter.NewOrchCutHistoryExporter(orchAddr, watchlist, cutHistoryFetchInterval, cutHistoryUpdateInterval)
+	var orchWatchlistExporter *orch_watchlist_exporter.OrchWatchlistExporter
+	if len(watchlist) > 0 {
+		orchWatchlistExporter = orch_watchlist_exporter.NewOrchWatchlistExporter(orchAddr, watchlist, watchlistFetchInterval, watchlistUpdateInterval)
+	}
+
+	// Run the export command instead of the exporter, if requested.
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		if err := runExport(os.Args[2:], orchTicketsExporter, orchRewardsExporter, priceProvider, earningsCurrency); err != nil {
+			log.Fatalf("Error exporting earnings: %v", err)
+		}
+		return
+	}
 
 	// Start sub-exporters.
 	log.Println("Starting sub exporters...")
@@ -126,10 +384,34 @@ func main() {
 	go orchTicketsExporter.Start()
 	go orchRewardsExporter.Start()
 	go cryptoPricesExporter.Start()
+	go orchProfitExporter.Start()
+	go orchForecastExporter.Start()
+	go protocolExporter.Start()
+	go networkPricingExporter.Start()
+	go orchBondEventsExporter.Start()
+	go orchUnbondingExporter.Start()
+	go orchFeeWithdrawalsExporter.Start()
+	go orchWalletExporter.Start()
+	go orchGasExporter.Start()
+	go orchRewardProfitabilityExporter.Start()
+	go orchCutHistoryExporter.Start()
+	if orchWatchlistExporter != nil {
+		go orchWatchlistExporter.Start()
+	}
 
 	// Expose the registered metrics via HTTP.
 	log.Println("Exposing metrics via HTTP on port 9153")
-	http.Handle("/metrics", promhttp.Handler())
+	gatherers := prometheus.Gatherers{prometheus.DefaultGatherer}
+	if orchWatchlistExporter != nil {
+		gatherers = append(gatherers, orchWatchlistExporter.Gatherer())
+	}
+	http.Handle("/metrics", promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer, promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{})))
+	earningsReport := earnings.NewEarningsReport(earningsCurrency, orchTicketsExporter, orchRewardsExporter, priceProvider)
+	http.HandleFunc("/export/earnings.csv", earningsReport.ServeCSV)
+	http.HandleFunc("/delegators/earnings", orchDelegatorsExporter.ServeEarnings)
+	http.HandleFunc("/rewards/missed-rounds", orchInfoExporter.ServeMissedRewardRounds)
+	cutSimulator := simulator.NewSimulator(orchInfoExporter, orchTicketsExporter)
+	http.HandleFunc("/simulate", cutSimulator.ServeSimulation)
 	err = http.ListenAndServe(":9153", nil)
 	if err != nil {
 		log.Fatalf("Server failed to start: %v", err)
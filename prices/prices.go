@@ -0,0 +1,157 @@
+// Package prices provides a historical crypto price provider that fetches daily prices from the
+// https://api.coinbase.com/v2/prices/{pair}/spot API endpoint and caches them locally.
+package prices
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"livepeer-exporter/fetcher"
+	"livepeer-exporter/util"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	historicalPriceEndpointTemplate = "https://api.coinbase.com/v2/prices/%s-%s/spot?date=%s"
+)
+
+// dateLayout is the layout used for the dates in the API requests and the cache keys.
+const dateLayout = "2006-01-02"
+
+// spotPriceResponse represents the structure of the data returned by the API.
+type spotPriceResponse struct {
+	Data struct {
+		Base     string
+		Currency string
+		Amount   string
+	}
+}
+
+// HistoricalPriceProvider retrieves the daily price of crypto currencies and caches them in memory
+// and, when a cache path is set, on disk.
+type HistoricalPriceProvider struct {
+	mutex sync.Mutex
+
+	// Config settings.
+	cachePath string // The path of the file the cache is persisted to.
+
+	// Data.
+	cache map[string]float64 // The cached prices keyed by asset, currency and date.
+	dirty bool               // Whether the cache contains prices that are not persisted yet.
+}
+
+// cacheKey returns the cache key for the price of an asset in a currency on a given date.
+func cacheKey(asset string, currency string, date string) string {
+	return fmt.Sprintf("%s-%s-%s", asset, currency, date)
+}
+
+// loadCache loads the price cache from the cache file.
+func (p *HistoricalPriceProvider) loadCache() error {
+	data, err := os.ReadFile(p.cachePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("error reading price cache '%s': %w", p.cachePath, err)
+	}
+	if err := json.Unmarshal(data, &p.cache); err != nil {
+		return fmt.Errorf("error decoding price cache '%s': %w", p.cachePath, err)
+	}
+
+	// A cache file containing 'null' decodes into a nil map.
+	if p.cache == nil {
+		p.cache = map[string]float64{}
+	}
+	return nil
+}
+
+// saveCache persists the price cache to the cache file.
+func (p *HistoricalPriceProvider) saveCache() error {
+	data, err := json.Marshal(p.cache)
+	if err != nil {
+		return fmt.Errorf("error encoding price cache: %w", err)
+	}
+	if err := os.WriteFile(p.cachePath, data, 0644); err != nil {
+		return fmt.Errorf("error writing price cache '%s': %w", p.cachePath, err)
+	}
+	return nil
+}
+
+// GetPrice returns the price of an asset in the given currency on the day of the given time.
+// Prices of past days are cached, while prices of the current day are always fetched since they
+// are not final yet. New prices are only persisted when Save is called.
+func (p *HistoricalPriceProvider) GetPrice(asset string, currency string, t time.Time) (float64, error) {
+	asset, currency = strings.ToUpper(asset), strings.ToUpper(currency)
+	date := t.UTC().Format(dateLayout)
+	isFinal := date < time.Now().UTC().Format(dateLayout)
+
+	// Return cached price, if any.
+	key := cacheKey(asset, currency, date)
+	p.mutex.Lock()
+	price, ok := p.cache[key]
+	p.mutex.Unlock()
+	if ok && isFinal {
+		return price, nil
+	}
+
+	// Fetch the price from the API.
+	response := &spotPriceResponse{}
+	priceFetcher := fetcher.Fetcher{
+		URL:  fmt.Sprintf(historicalPriceEndpointTemplate, asset, currency, date),
+		Data: response,
+	}
+	if err := priceFetcher.FetchData(); err != nil {
+		return 0, fmt.Errorf("error fetching %s-%s price for %s: %w", asset, currency, date, err)
+	}
+	price, err := util.StringToFloat64(response.Data.Amount)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing %s-%s price for %s: %w", asset, currency, date, err)
+	}
+
+	// Cache the price.
+	if isFinal {
+		p.mutex.Lock()
+		p.cache[key] = price
+		p.dirty = true
+		p.mutex.Unlock()
+	}
+
+	return price, nil
+}
+
+// Save persists the prices that were cached since the last save to the cache file, if set.
+func (p *HistoricalPriceProvider) Save() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.cachePath == "" || !p.dirty {
+		return nil
+	}
+	if err := p.saveCache(); err != nil {
+		return err
+	}
+	p.dirty = false
+	return nil
+}
+
+// NewHistoricalPriceProvider creates a new HistoricalPriceProvider. When cachePath is not empty,
+// previously cached prices are loaded from and new prices are persisted to this file.
+func NewHistoricalPriceProvider(cachePath string) *HistoricalPriceProvider {
+	provider := &HistoricalPriceProvider{
+		cachePath: cachePath,
+		cache:     map[string]float64{},
+	}
+
+	// Load the persisted cache.
+	if cachePath != "" {
+		if err := provider.loadCache(); err != nil {
+			log.Printf("Error loading price cache: %v", err)
+		}
+	}
+
+	return provider
+}
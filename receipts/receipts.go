@@ -0,0 +1,134 @@
+// Package receipts provides a transaction receipt provider that fetches the receipts of Arbitrum
+// transactions from a JSON-RPC endpoint and caches their gas usage and L1 and L2 gas costs in memory.
+package receipts
+
+import (
+	"fmt"
+	"livepeer-exporter/fetcher"
+	"livepeer-exporter/util"
+	"log"
+	"sync"
+)
+
+// transactionReceipt represents the structure of the eth_getTransactionReceipt result. The gasUsedForL1 field
+// is specific to Arbitrum and holds the part of the used gas that pays for posting the transaction to L1. It
+// is not set in the receipts of transactions from before the Arbitrum Nitro upgrade.
+type transactionReceipt struct {
+	GasUsed           string
+	GasUsedForL1      string
+	EffectiveGasPrice string
+}
+
+// Receipt represents the gas usage and the L1 and L2 gas cost of a transaction.
+type Receipt struct {
+	GasUsed float64 // The gas used by the transaction.
+	L1Cost  float64 // The gas cost of posting the transaction to L1 in ETH.
+	L2Cost  float64 // The gas cost of executing the transaction on L2 in ETH.
+}
+
+// GasCost returns the total gas cost of the transaction in ETH.
+func (r Receipt) GasCost() float64 {
+	return r.L1Cost + r.L2Cost
+}
+
+// parseReceipt returns the gas usage and the L1 and L2 gas cost of a transaction from its receipt.
+// Receipts without the gas used for L1 are attributed entirely to L2.
+func parseReceipt(receipt transactionReceipt) (Receipt, error) {
+	gasUsed, ok := util.ParseQuantity(receipt.GasUsed)
+	if !ok {
+		return Receipt{}, fmt.Errorf("invalid gas used '%s'", receipt.GasUsed)
+	}
+	var gasUsedForL1 float64
+	if receipt.GasUsedForL1 != "" {
+		gasUsedForL1, ok = util.ParseQuantity(receipt.GasUsedForL1)
+		if !ok {
+			return Receipt{}, fmt.Errorf("invalid gas used for L1 '%s'", receipt.GasUsedForL1)
+		}
+	}
+	gasPrice, ok := util.ParseQuantity(receipt.EffectiveGasPrice)
+	if !ok {
+		return Receipt{}, fmt.Errorf("invalid effective gas price '%s'", receipt.EffectiveGasPrice)
+	}
+	return Receipt{
+		GasUsed: gasUsed,
+		L1Cost:  gasUsedForL1 * gasPrice / 1e18,
+		L2Cost:  (gasUsed - gasUsedForL1) * gasPrice / 1e18,
+	}, nil
+}
+
+// ReceiptProvider retrieves the receipts of transactions and caches them in memory. Since receipts do
+// not change, each receipt is only fetched once.
+type ReceiptProvider struct {
+	mutex sync.Mutex
+
+	// Config settings.
+	rpcURL string // The JSON-RPC endpoint to fetch the receipts from.
+
+	// Data.
+	receipts map[string]Receipt // The cached receipts keyed by transaction hash.
+}
+
+// NewReceiptProvider creates a new ReceiptProvider.
+func NewReceiptProvider(rpcURL string) *ReceiptProvider {
+	return &ReceiptProvider{
+		rpcURL:   rpcURL,
+		receipts: make(map[string]Receipt),
+	}
+}
+
+// fetchReceipt fetches the receipt of the given transaction from the JSON-RPC endpoint.
+func (p *ReceiptProvider) fetchReceipt(id string) (Receipt, error) {
+	var receipt transactionReceipt
+	receiptFetcher := fetcher.Fetcher{
+		URL:  p.rpcURL,
+		Data: &receipt,
+	}
+	if err := receiptFetcher.FetchJSONRPCData("eth_getTransactionReceipt", id); err != nil {
+		return Receipt{}, err
+	}
+	return parseReceipt(receipt)
+}
+
+// Fetch fetches the receipts of the given transactions that are not cached yet. Receipts that could not
+// be fetched are retried on the next call.
+func (p *ReceiptProvider) Fetch(ids []string) {
+	for _, id := range ids {
+		if _, ok := p.GetReceipt(id); ok {
+			continue
+		}
+
+		receipt, err := p.fetchReceipt(id)
+		if err != nil {
+			log.Printf("Error fetching receipt of transaction '%s': %v", id, err)
+			continue
+		}
+		p.mutex.Lock()
+		p.receipts[id] = receipt
+		p.mutex.Unlock()
+	}
+}
+
+// GetReceipt returns the cached receipt of the given transaction. The returned bool is false when the
+// receipt was not fetched yet.
+func (p *ReceiptProvider) GetReceipt(id string) (Receipt, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	receipt, ok := p.receipts[id]
+	return receipt, ok
+}
+
+// GetGasCosts returns the gas costs in ETH of the given transactions keyed by transaction hash. The
+// returned bool is false when the receipt of any of the transactions was not fetched yet.
+func (p *ReceiptProvider) GetGasCosts(ids []string) (map[string]float64, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	gasCosts := make(map[string]float64, len(ids))
+	for _, id := range ids {
+		receipt, ok := p.receipts[id]
+		if !ok {
+			return nil, false
+		}
+		gasCosts[id] = receipt.GasCost()
+	}
+	return gasCosts, true
+}
@@ -0,0 +1,165 @@
+// Package simulator implements a what-if simulator that projects the income of the Livepeer orchestrator
+// and the APR of its delegators under a proposed reward and fee cut. It combines the orchestrator info and
+// reward pools of the orch_info_exporter with the winning tickets of the orch_tickets_exporter.
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"livepeer-exporter/exporters/orch_info_exporter"
+	"livepeer-exporter/exporters/orch_tickets_exporter"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// The lookback windows the projections are based on.
+const (
+	lookbackRounds = 30 // The number of rounds of reward pools.
+	lookbackDays   = 30 // The number of days of winning tickets.
+)
+
+// Scenario represents the projected yearly income of the orchestrator and its delegators for a given
+// reward and fee cut.
+type Scenario struct {
+	RewardCut           float64 `json:"rewardCut"`           // The proportion of the rewards the orchestrator takes.
+	FeeCut              float64 `json:"feeCut"`              // The proportion of the fees the orchestrator takes.
+	OrchRewardsLPT      float64 `json:"orchRewardsLPT"`      // The yearly LPT rewards of the orchestrator.
+	OrchFeesETH         float64 `json:"orchFeesETH"`         // The yearly ETH fees of the orchestrator.
+	DelegatorRewardsLPT float64 `json:"delegatorRewardsLPT"` // The yearly LPT rewards of the other delegators.
+	DelegatorFeesETH    float64 `json:"delegatorFeesETH"`    // The yearly ETH fees of the other delegators.
+	DelegatorAPR        float64 `json:"delegatorAPR"`        // The LPT reward APR of the delegators.
+}
+
+// Simulation represents the result of a simulation.
+type Simulation struct {
+	LookbackRounds int      `json:"lookbackRounds"` // The number of rounds of reward pools used for the projections.
+	LookbackDays   int      `json:"lookbackDays"`   // The number of days of winning tickets used for the projections.
+	Current        Scenario `json:"current"`        // The projection under the current cuts.
+	Proposed       Scenario `json:"proposed"`       // The projection under the proposed cuts.
+}
+
+// Simulator projects the orchestrator's income under different reward and fee cuts.
+type Simulator struct {
+	// Data sources.
+	orchInfoExporter    *orch_info_exporter.OrchInfoExporter       // Provides the orchestrator's stake, cuts and reward pools.
+	orchTicketsExporter *orch_tickets_exporter.OrchTicketsExporter // Provides the orchestrator's fees.
+}
+
+// getYearlyEarnings returns the projected yearly LPT rewards and ETH fees of the orchestrator's pool.
+// The rewards are based on the reward pools and the fees on the winning tickets of the lookback window.
+// It returns an error until all winning tickets were fetched, since the fees would be underestimated.
+func (s *Simulator) getYearlyEarnings(orchInfo orch_info_exporter.OrchInfo) (rewards float64, fees float64, err error) {
+	if !s.orchTicketsExporter.Complete() {
+		return 0, 0, fmt.Errorf("winning tickets are incomplete")
+	}
+
+	poolRewards, _, rounds := s.orchInfoExporter.PoolEarnings(lookbackRounds)
+	if rounds > 0 {
+		rewards = poolRewards / float64(rounds) * orchInfo.RoundsPerYear()
+	}
+
+	lookbackStart := time.Now().AddDate(0, 0, -lookbackDays)
+	for _, ticket := range s.orchTicketsExporter.Tickets() {
+		if !ticket.Timestamp.Before(lookbackStart) {
+			fees += ticket.FaceValue
+		}
+	}
+	return rewards, fees * 365 / lookbackDays, nil
+}
+
+// project projects the income of the orchestrator and its delegators for the given cuts. The
+// orchestrator keeps its cut and receives a share of the remainder for its own stake.
+func project(orchInfo orch_info_exporter.OrchInfo, rewards, fees, rewardCut, feeCut float64) Scenario {
+	var ownPoolShare float64
+	if orchInfo.TotalStake > 0 {
+		ownPoolShare = orchInfo.OrchStake / orchInfo.TotalStake
+	}
+
+	scenario := Scenario{
+		RewardCut:           rewardCut,
+		FeeCut:              feeCut,
+		OrchRewardsLPT:      rewards*rewardCut + rewards*(1-rewardCut)*ownPoolShare,
+		OrchFeesETH:         fees*feeCut + fees*(1-feeCut)*ownPoolShare,
+		DelegatorRewardsLPT: rewards * (1 - rewardCut) * (1 - ownPoolShare),
+		DelegatorFeesETH:    fees * (1 - feeCut) * (1 - ownPoolShare),
+	}
+	if orchInfo.TotalStake > 0 {
+		scenario.DelegatorAPR = rewards * (1 - rewardCut) / orchInfo.TotalStake
+	}
+	return scenario
+}
+
+// parseCut parses the cut query parameter with the given name. It returns the default value if the
+// parameter is not set.
+func parseCut(req *http.Request, name string, defaultValue float64) (float64, error) {
+	value := req.URL.Query().Get(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	cut, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(cut) || cut < 0 || cut > 1 {
+		return 0, fmt.Errorf("'%s' should be a proportion between 0 and 1", name)
+	}
+	return cut, nil
+}
+
+// Simulate projects the income of the orchestrator and its delegators under the current and the given cuts.
+func (s *Simulator) Simulate(rewardCut, feeCut float64) (Simulation, error) {
+	orchInfo := s.orchInfoExporter.OrchInfo()
+	rewards, fees, err := s.getYearlyEarnings(orchInfo)
+	if err != nil {
+		return Simulation{}, err
+	}
+	return Simulation{
+		LookbackRounds: lookbackRounds,
+		LookbackDays:   lookbackDays,
+		Current:        project(orchInfo, rewards, fees, orchInfo.RewardCut, orchInfo.FeeCut),
+		Proposed:       project(orchInfo, rewards, fees, rewardCut, feeCut),
+	}, nil
+}
+
+// ServeSimulation serves the simulation for the 'reward_cut' and 'fee_cut' query parameters as JSON.
+// Cuts that are not set default to the current cuts.
+func (s *Simulator) ServeSimulation(w http.ResponseWriter, req *http.Request) {
+	orchInfo := s.orchInfoExporter.OrchInfo()
+	rewardCut, err := parseCut(req, "reward_cut", orchInfo.RewardCut)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	feeCut, err := parseCut(req, "fee_cut", orchInfo.FeeCut)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := s.Simulate(rewardCut, feeCut)
+	if err != nil {
+		log.Printf("Error simulating cuts: %v", err)
+		http.Error(w, "simulation unavailable until all winning tickets were fetched", http.StatusServiceUnavailable)
+		return
+	}
+
+	// Encode the simulation before writing the response so that encoding errors can still be reported.
+	simulation, err := json.Marshal(result)
+	if err != nil {
+		log.Printf("Error encoding simulation: %v", err)
+		http.Error(w, "error encoding simulation", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(simulation); err != nil {
+		log.Printf("Error writing simulation: %v", err)
+	}
+}
+
+// NewSimulator creates a new Simulator.
+func NewSimulator(orchInfoExporter *orch_info_exporter.OrchInfoExporter, orchTicketsExporter *orch_tickets_exporter.OrchTicketsExporter) *Simulator {
+	return &Simulator{
+		orchInfoExporter:    orchInfoExporter,
+		orchTicketsExporter: orchTicketsExporter,
+	}
+}
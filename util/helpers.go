@@ -9,9 +9,12 @@ import (
 	"io"
 	"log"
 	"math"
+	"math/big"
 	"net/http"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"livepeer-exporter/constants"
@@ -32,6 +35,105 @@ func Round(value float64, decimals int) float64 {
 	return math.Round(value*shift) / shift
 }
 
+// Percentile returns the p-th percentile (0-100) of the given values using linear interpolation
+// between the closest ranks. It returns 0 if no values are given.
+func Percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+// Median returns the median of the given values. It returns 0 if no values are given.
+func Median(values []float64) float64 {
+	return Percentile(values, 50)
+}
+
+// PercentileRank returns the percentage (0-100) of the given values that are lower than the given
+// value, counting equal values as half. It returns 0 if no values are given.
+func PercentileRank(values []float64, value float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var below, equal float64
+	for _, v := range values {
+		if v < value {
+			below++
+		} else if v == value {
+			equal++
+		}
+	}
+	return (below + 0.5*equal) / float64(len(values)) * 100
+}
+
+// Gini returns the Gini coefficient of the given non-negative values, where 0 means that all values are
+// equal. When a single value holds the total, it returns the maximum of (n-1)/n for n values, which only
+// approaches 1 for many values. It returns 0 if the total is 0.
+func Gini(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	var total, weightedTotal float64
+	for i, v := range sorted {
+		total += v
+		weightedTotal += float64(i+1) * v
+	}
+	if total == 0 {
+		return 0
+	}
+	n := float64(len(sorted))
+	return (2*weightedTotal)/(n*total) - (n+1)/n
+}
+
+// Mean returns the arithmetic mean of the given values. It returns 0 if no values are given.
+func Mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+	return total / float64(len(values))
+}
+
+// StdDev returns the population standard deviation of the given values. It returns 0 if no values
+// are given.
+func StdDev(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	mean := Mean(values)
+	var sumSquares float64
+	for _, v := range values {
+		sumSquares += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sumSquares / float64(len(values)))
+}
+
+// LinearRegression returns the intercept and slope of the least squares line through the given values,
+// using their index as x-coordinate. The slope is 0 if less than two values are given.
+func LinearRegression(values []float64) (intercept float64, slope float64) {
+	n := float64(len(values))
+	if n < 2 {
+		return Mean(values), 0
+	}
+	meanX, meanY := (n-1)/2, Mean(values)
+	var covariance, varianceX float64
+	for i, v := range values {
+		covariance += (float64(i) - meanX) * (v - meanY)
+		varianceX += (float64(i) - meanX) * (float64(i) - meanX)
+	}
+	slope = covariance / varianceX
+	return meanY - slope*meanX, slope
+}
+
 // StringToFloat64 parses a string to a float64.
 // If the string cannot be parsed, it returns an error.
 func StringToFloat64(s string) (float64, error) {
@@ -53,6 +155,35 @@ func SetFloatFromStr(dest *float64, source string) {
 	*dest = temp
 }
 
+// ParseQuantity parses a hexadecimal JSON-RPC quantity into a float64.
+func ParseQuantity(hexQuantity string) (float64, bool) {
+	quantity, ok := new(big.Int).SetString(hexQuantity, 0)
+	if !ok {
+		return 0, false
+	}
+	value, _ := new(big.Float).SetInt(quantity).Float64()
+	return value, true
+}
+
+// Period represents a lookback period over which metrics are aggregated.
+type Period struct {
+	Name  string    // The name of the period, used as metric label value.
+	Start time.Time // The start time of the period.
+}
+
+// GetPeriods returns the lookback periods, relative to the given time, over which the period metrics
+// are aggregated. The 'total' period covers all data.
+func GetPeriods(now time.Time) []Period {
+	return []Period{
+		{Name: "day", Start: now.AddDate(0, 0, -1)},
+		{Name: "week", Start: now.AddDate(0, 0, -7)},
+		{Name: "thirty_day", Start: now.AddDate(0, -1, 0)},
+		{Name: "ninety_day", Start: now.AddDate(0, -3, 0)},
+		{Name: "year", Start: now.AddDate(-1, 0, 0)},
+		{Name: "total", Start: time.Time{}},
+	}
+}
+
 // getEnvVarDuration retrieves a duration from an environment variable.
 func GetEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	valueStr := os.Getenv(key)
@@ -66,6 +197,86 @@ func GetEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	return value
 }
 
+// GetEnvFloat retrieves a float64 from an environment variable.
+func GetEnvFloat(key string, defaultValue float64) float64 {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		log.Fatalf("failed to parse '%s' environment variable: %v", key, err)
+	}
+	return value
+}
+
+// GetEnvInt retrieves an int from an environment variable.
+func GetEnvInt(key string, defaultValue int) int {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := strconv.Atoi(valueStr)
+	if err != nil {
+		log.Fatalf("failed to parse '%s' environment variable: %v", key, err)
+	}
+	return value
+}
+
+// GetEnvIntSlice retrieves a comma-separated list of ints from an environment variable.
+func GetEnvIntSlice(key string, defaultValue []int) []int {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	var values []int
+	for _, entry := range strings.Split(valueStr, ",") {
+		value, err := strconv.Atoi(strings.TrimSpace(entry))
+		if err != nil {
+			log.Fatalf("failed to parse '%s' environment variable: %v", key, err)
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// GetEnvString retrieves a string from an environment variable.
+func GetEnvString(key string, defaultValue string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+// NamedAddress represents an Ethereum address with an optional human-readable name.
+type NamedAddress struct {
+	Address string
+	Name    string
+}
+
+// ParseNamedAddresses parses a comma-separated list of addresses with optional names in the
+// 'address:name' format (e.g. '0xabc:my-orch,0xdef') into a slice of NamedAddresses. Addresses are
+// lowercased and, when no name is given, the address is used as name.
+func ParseNamedAddresses(value string) []NamedAddress {
+	var addresses []NamedAddress
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		address, name, _ := strings.Cut(entry, ":")
+		address = strings.ToLower(strings.TrimSpace(address))
+		name = strings.TrimSpace(name)
+		if name == "" {
+			name = address
+		}
+		addresses = append(addresses, NamedAddress{Address: address, Name: name})
+	}
+	return addresses
+}
+
 // graphQLRequest represents the structure of the GraphQL API request used in IsOrchestrator.
 type GraphQLRequest struct {
 	Query string `json:"query"`
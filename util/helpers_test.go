@@ -0,0 +1,112 @@
+package util
+
+import (
+	"math"
+	"testing"
+)
+
+// almostEqual reports whether the given floats are equal within a small tolerance.
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		p      float64
+		want   float64
+	}{
+		{"no values", nil, 50, 0},
+		{"single value", []float64{7}, 90, 7},
+		{"minimum", []float64{3, 1, 2}, 0, 1},
+		{"maximum", []float64{3, 1, 2}, 100, 3},
+		{"exact rank", []float64{5, 1, 3}, 50, 3},
+		{"interpolated", []float64{1, 2, 3, 4}, 50, 2.5},
+		{"interpolated high percentile", []float64{10, 20, 30, 40, 50}, 90, 46},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Percentile(tt.values, tt.p); !almostEqual(got, tt.want) {
+				t.Errorf("Percentile(%v, %v) = %v, want %v", tt.values, tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPercentileDoesNotSortInput(t *testing.T) {
+	values := []float64{3, 1, 2}
+	Percentile(values, 50)
+	if values[0] != 3 || values[1] != 1 || values[2] != 2 {
+		t.Errorf("Percentile modified its input: %v", values)
+	}
+}
+
+func TestPercentileRank(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		value  float64
+		want   float64
+	}{
+		{"no values", nil, 1, 0},
+		{"lowest", []float64{2, 3, 4}, 1, 0},
+		{"highest", []float64{2, 3, 4}, 5, 100},
+		{"between", []float64{1, 2, 3, 4}, 2.5, 50},
+		{"equal counts half", []float64{1, 2, 3, 4}, 2, 37.5},
+		{"all equal", []float64{2, 2}, 2, 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PercentileRank(tt.values, tt.value); !almostEqual(got, tt.want) {
+				t.Errorf("PercentileRank(%v, %v) = %v, want %v", tt.values, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGini(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   float64
+	}{
+		{"no values", nil, 0},
+		{"zero total", []float64{0, 0, 0}, 0},
+		{"equal values", []float64{5, 5, 5, 5}, 0},
+		{"single holder of two", []float64{0, 10}, 0.5},
+		{"single holder of four", []float64{0, 0, 10, 0}, 0.75},
+		{"unequal values", []float64{1, 2, 3, 4}, 0.25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Gini(tt.values); !almostEqual(got, tt.want) {
+				t.Errorf("Gini(%v) = %v, want %v", tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinearRegression(t *testing.T) {
+	tests := []struct {
+		name          string
+		values        []float64
+		wantIntercept float64
+		wantSlope     float64
+	}{
+		{"no values", nil, 0, 0},
+		{"single value", []float64{4}, 4, 0},
+		{"constant", []float64{2, 2, 2}, 2, 0},
+		{"increasing line", []float64{1, 3, 5, 7}, 1, 2},
+		{"decreasing line", []float64{6, 4, 2}, 6, -2},
+		{"noisy", []float64{1, 2, 2, 3}, 1.1, 0.6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			intercept, slope := LinearRegression(tt.values)
+			if !almostEqual(intercept, tt.wantIntercept) || !almostEqual(slope, tt.wantSlope) {
+				t.Errorf("LinearRegression(%v) = (%v, %v), want (%v, %v)", tt.values, intercept, slope, tt.wantIntercept, tt.wantSlope)
+			}
+		})
+	}
+}